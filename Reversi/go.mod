@@ -0,0 +1,7 @@
+module reversi
+
+go 1.18
+
+require mcts v0.0.0
+
+replace mcts => ../mcts
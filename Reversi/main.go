@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	arena "mcts/arena"
+	mcts "mcts/mcts"
+	reversi "reversi/reversi"
+)
+
+const (
+	playTimes  = 100
+	selfPlay   = false
+	iterations = 1000 // AI每步的MCTS迭代次數
+	seed       = 0    // 亂數種子，0時使用目前時間，固定種子可以重現自我對弈與搜尋結果
+)
+
+func main() {
+	if selfPlay {
+		newGame := func() mcts.Game { return reversi.New() }
+		rng := newRand()
+		result, err := arena.Play(newGame, arena.MCTSPlayer{Iterations: iterations, Rand: rng}, arena.MCTSPlayer{Iterations: 1, Rand: rng}, arena.Options{
+			Games:        playTimes,
+			OpeningPlies: 2,
+			MoveString:   reversi.MoveString,
+			OnGame: func(record arena.GameRecord) {
+				order := "後手"
+				if record.AFirst {
+					order = "先手"
+				}
+				fmt.Printf("第%d局 玩家1(%s) %s\n", record.Index+1, order, record.Outcome)
+			},
+		})
+		if err != nil {
+			fmt.Println("對戰失敗:", err)
+			return
+		}
+		result.WriteText(os.Stdout)
+	} else {
+		game := playWithAI()
+		p1, p2 := game.CountDiscs()
+		fmt.Printf("黑子(玩家) %d : 白子(AI) %d\n", p1, p2)
+		switch game.GetGameState().Winner {
+		case reversi.None:
+			fmt.Println("平手")
+		case reversi.Player1:
+			fmt.Println("玩家勝利")
+		case reversi.Player2:
+			fmt.Println("AI勝利")
+		default:
+			fmt.Println("未定義的遊戲結果")
+		}
+	}
+}
+
+// 以seed建立亂數來源，seed為0時使用目前時間
+func newRand() *rand.Rand {
+	if seed != 0 {
+		return rand.New(rand.NewSource(seed))
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+func playWithAI() *reversi.GameState {
+	reader := bufio.NewReader(os.Stdin)
+	game := reversi.New()
+	rng := newRand()
+	for !game.GetGameState().IsTerminal {
+		if game.CurrentPlayer() == reversi.Player1 { // 玩家1行動
+			pos := getPlayerInput(reader, game)
+			game.ApplyMove(pos)
+			fmt.Println("玩家 放置旗子在位置", reversi.MoveString(pos))
+		} else { //玩家2行動
+			result, err := mcts.Analyze(context.Background(), game, mcts.Options{MaxIterations: iterations, Solver: true, Transpositions: true, Rand: rng})
+			if err != nil {
+				fmt.Println("AI搜尋失敗:", err)
+				return game
+			}
+			fmt.Println(result.Format(reversi.MoveString))
+			pos := result.Move
+			game.ApplyMove(pos)
+			fmt.Println(game.DrawTable())
+			fmt.Println("AI 放置旗子在位置", reversi.MoveString(pos))
+		}
+	}
+	fmt.Println(game.DrawTable())
+	return game
+}
+
+// 取得玩家輸入
+func getPlayerInput(reader *bufio.Reader, state *reversi.GameState) int {
+	legalMoves := state.GetLegalMoves()
+	// 無子可下時自動虛手
+	if len(legalMoves) == 1 && legalMoves[0] == reversi.Pass {
+		fmt.Println("無子可下，自動虛手(pass)")
+		return reversi.Pass
+	}
+	for {
+		// 請求玩家輸入
+		fmt.Println(state.DrawTable())
+		fmt.Println("請輸入你想放置棋子的位置(例如d3):")
+
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			fmt.Println("輸入已結束，離開遊戲")
+			os.Exit(0)
+		}
+		if err != nil {
+			fmt.Println("輸入有誤，請重新輸入")
+			continue
+		}
+		pos, err := reversi.ParseMove(line)
+		if err != nil {
+			fmt.Println("輸入範圍有誤，請輸入a1-h8之間的座標")
+			continue
+		}
+
+		// 檢查選擇的位置是否能夾住對手的棋子
+		if !state.IsLegalMove(pos) {
+			fmt.Println("該位置無法落子，請選擇其他位置")
+			continue
+		}
+		return pos
+	}
+}
@@ -0,0 +1,187 @@
+package reversi
+
+import (
+	"math/bits"
+
+	mcts "mcts/mcts"
+)
+
+// 以位元表示的棋況：masks[0]、masks[1]分別是黑子、白子，第pos(=列*Size+行)個位元代表位置pos
+// 合法動作與翻子都以八個方向的位移一次算出整個棋盤，下棋時同步更新Zobrist雜湊值
+type Bitboard struct {
+	masks      [2]uint64
+	Player     int    // 目前換哪位玩家行動
+	moves      int    // 已執行的動作數(包含虛手)
+	hash       uint64 // Zobrist雜湊值，與GameState.Hash相同
+	LastPlaced int
+}
+
+const (
+	notFileA uint64 = 0xfefefefefefefefe // 去掉a行(第0行)的遮罩，往右移動後使用
+	notFileH uint64 = 0x7f7f7f7f7f7f7f7f // 去掉h行(第7行)的遮罩，往左移動後使用
+)
+
+// 八個方向的位移，移出棋盤或跨行繞回的位元會被去掉
+var shifts = [8]func(uint64) uint64{
+	func(b uint64) uint64 { return (b >> 9) & notFileH }, // 左上
+	func(b uint64) uint64 { return b >> 8 },              // 上
+	func(b uint64) uint64 { return (b >> 7) & notFileA }, // 右上
+	func(b uint64) uint64 { return (b >> 1) & notFileH }, // 左
+	func(b uint64) uint64 { return (b << 1) & notFileA }, // 右
+	func(b uint64) uint64 { return (b << 7) & notFileH }, // 左下
+	func(b uint64) uint64 { return b << 8 },              // 下
+	func(b uint64) uint64 { return (b << 9) & notFileA }, // 右下
+}
+
+var (
+	_ mcts.Game   = (*Bitboard)(nil)
+	_ mcts.Hasher = (*Bitboard)(nil)
+)
+
+// 建立新的一局位元棋況
+func NewBitboard() *Bitboard {
+	return BitboardFrom(New())
+}
+
+// 將GameState轉成位元棋況，GameState沒有記錄虛手，動作數以初始四子之外的棋子數計算
+func BitboardFrom(state *GameState) *Bitboard {
+	b := &Bitboard{Player: state.Player, LastPlaced: state.LastPlaced, hash: state.Hash()}
+	for pos, player := range state.Board {
+		if player != None {
+			b.masks[player-1] |= 1 << pos
+		}
+	}
+	b.moves = bits.OnesCount64(b.masks[0]|b.masks[1]) - 4
+	return b
+}
+
+// 已執行的動作數(包含虛手)
+func (b *Bitboard) Moves() int {
+	return b.moves
+}
+
+// 轉回GameState
+func (b *Bitboard) GameState() *GameState {
+	state := &GameState{Player: b.Player, LastPlaced: b.LastPlaced}
+	for pos := range state.Board {
+		switch bit := uint64(1) << pos; {
+		case b.masks[0]&bit != 0:
+			state.Board[pos] = Player1
+		case b.masks[1]&bit != 0:
+			state.Board[pos] = Player2
+		}
+	}
+	return state
+}
+
+// player可以落子的位置遮罩
+func (b *Bitboard) validMask(player int) uint64 {
+	own, opp := b.masks[player-1], b.masks[2-player]
+	empty := ^(own | opp)
+	var moves uint64
+	for _, shift := range shifts {
+		// 沿著方向連續的對手棋子，最多6顆
+		x := shift(own) & opp
+		for i := 0; i < 5; i++ {
+			x |= shift(x) & opp
+		}
+		moves |= shift(x) & empty
+	}
+	return moves
+}
+
+// player在pos落子時被翻轉的棋子遮罩
+func (b *Bitboard) flipMask(player, pos int) uint64 {
+	own, opp := b.masks[player-1], b.masks[2-player]
+	var flipped uint64
+	for _, shift := range shifts {
+		var line uint64
+		x := shift(uint64(1) << pos)
+		for x&opp != 0 {
+			line |= x
+			x = shift(x)
+		}
+		if x&own != 0 {
+			flipped |= line
+		}
+	}
+	return flipped
+}
+
+// 取得目前玩家可執行的動作，無子可下但棋局未結束時只能虛手(實作mcts.Game)
+func (b *Bitboard) GetLegalMoves() []int {
+	valid := b.validMask(b.Player)
+	if valid == 0 {
+		if b.validMask(Opponent(b.Player)) != 0 {
+			return []int{Pass}
+		}
+		return nil // 雙方都無子可下，棋局已結束
+	}
+	moves := make([]int, 0, bits.OnesCount64(valid))
+	for ; valid != 0; valid &= valid - 1 {
+		moves = append(moves, bits.TrailingZeros64(valid))
+	}
+	return moves
+}
+
+// 由目前玩家執行動作並翻轉被夾住的棋子(實作mcts.Game)
+func (b *Bitboard) ApplyMove(pos int) {
+	player, opponent := b.Player, Opponent(b.Player)
+	if pos != Pass {
+		flipped := b.flipMask(player, pos)
+		placed := flipped | uint64(1)<<pos
+		b.masks[player-1] |= placed
+		b.masks[opponent-1] &^= flipped
+		// 被翻轉的棋子從對手的值換成自己的值
+		b.hash ^= zobristKeys[pos][player]
+		for ; flipped != 0; flipped &= flipped - 1 {
+			p := bits.TrailingZeros64(flipped)
+			b.hash ^= zobristKeys[p][opponent] ^ zobristKeys[p][player]
+		}
+	}
+	b.LastPlaced = pos
+	b.Player = opponent
+	b.moves++
+	b.hash ^= zobristPlayer2
+}
+
+// 複製棋況(實作mcts.Game)
+func (b *Bitboard) Clone() mcts.Game {
+	newState := *b
+	return &newState
+}
+
+// 取得目前換哪位玩家行動(實作mcts.Game)
+func (b *Bitboard) CurrentPlayer() int {
+	return b.Player
+}
+
+// 計算雙方棋子數量
+func (b *Bitboard) CountDiscs() (player1, player2 int) {
+	return bits.OnesCount64(b.masks[0]), bits.OnesCount64(b.masks[1])
+}
+
+// 取得棋局是否結束與贏家(實作mcts.Game)，雙方都無子可下時棋局結束，棋子多的一方獲勝
+func (b *Bitboard) Result() (bool, int) {
+	if b.validMask(Player1) != 0 || b.validMask(Player2) != 0 {
+		return false, None
+	}
+	p1, p2 := b.CountDiscs()
+	switch {
+	case p1 > p2:
+		return true, Player1
+	case p2 > p1:
+		return true, Player2
+	}
+	return true, None
+}
+
+// 局面的雜湊值(實作mcts.Hasher)，下棋時已同步更新
+func (b *Bitboard) Hash() uint64 {
+	return b.hash
+}
+
+// 畫出棋況結果圖
+func (b *Bitboard) DrawTable() string {
+	return b.GameState().DrawTable()
+}
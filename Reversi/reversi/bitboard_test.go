@@ -0,0 +1,46 @@
+package reversi
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	gametest "mcts/gametest"
+	mcts "mcts/mcts"
+)
+
+// 隨機對局中Bitboard每一步都要跟GameState有相同的盤面、步數、合法動作(包含虛手)、雜湊值與結果
+func TestBitboardMatchesGameState(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	check := func(ply int, a, b mcts.Game) error {
+		state, board := a.(*GameState), b.(*Bitboard)
+		if *board.GameState() != *state {
+			return fmt.Errorf("盤面不同:\n%s/\n%s", state.DrawTable(), board.DrawTable())
+		}
+		if board.Moves() != ply {
+			return fmt.Errorf("動作數為%d，預期為%d", board.Moves(), ply)
+		}
+		return nil
+	}
+	for i := 0; i < 200; i++ {
+		if err := gametest.CompareRandomGame(rng, New(), NewBitboard(), check); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPlayoutGameState(b *testing.B) {
+	gametest.BenchmarkRandomPlayouts(b, func() mcts.Game { return New() })
+}
+
+func BenchmarkPlayoutBitboard(b *testing.B) {
+	gametest.BenchmarkRandomPlayouts(b, func() mcts.Game { return NewBitboard() })
+}
+
+func BenchmarkSearchGameState(b *testing.B) {
+	gametest.BenchmarkSearch(b, func() mcts.Game { return New() }, 2000)
+}
+
+func BenchmarkSearchBitboard(b *testing.B) {
+	gametest.BenchmarkSearch(b, func() mcts.Game { return NewBitboard() }, 2000)
+}
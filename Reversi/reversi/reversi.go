@@ -0,0 +1,218 @@
+package reversi
+
+import (
+	"fmt"
+	"strings"
+
+	mcts "mcts/mcts"
+)
+
+const (
+	None    = 0
+	Player1 = 1 // 黑子，先手
+	Player2 = 2 // 白子
+
+	Size = 8           // 棋盤邊長
+	Pass = Size * Size // 無子可下時的「虛手」動作
+)
+
+// 八個翻子方向(列差, 行差)
+var Directions = [8][2]int{
+	{-1, -1}, {-1, 0}, {-1, 1},
+	{0, -1}, {0, 1},
+	{1, -1}, {1, 0}, {1, 1},
+}
+
+// 棋局結果
+type GameResult struct {
+	IsTerminal bool
+	Winner     int
+}
+
+// 棋況
+type GameState struct {
+	Board      [Size * Size]int
+	Player     int // 目前換哪位玩家行動
+	LastPlaced int
+}
+
+// 建立新的一局棋況(中央四子交叉擺放，黑子先手)
+func New() *GameState {
+	state := &GameState{
+		Player:     Player1,
+		LastPlaced: -1,
+	}
+	state.Board[3*Size+3] = Player2
+	state.Board[3*Size+4] = Player1
+	state.Board[4*Size+3] = Player1
+	state.Board[4*Size+4] = Player2
+	return state
+}
+
+// 取得對手
+func Opponent(player int) int {
+	if player == Player1 {
+		return Player2
+	}
+	return Player1
+}
+
+// 計算player在pos落子時能翻轉的棋子位置，不合法時返回空slice
+func (t *GameState) flips(player, pos int) []int {
+	if pos < 0 || pos >= Size*Size || t.Board[pos] != None {
+		return nil
+	}
+	opponent := Opponent(player)
+	row, col := pos/Size, pos%Size
+	var flipped []int
+	for _, dir := range Directions {
+		var line []int
+		r, c := row+dir[0], col+dir[1]
+		// 沿著方向前進，收集連續的對手棋子，直到遇到自己的棋子才算夾住
+		for r >= 0 && r < Size && c >= 0 && c < Size && t.Board[r*Size+c] == opponent {
+			line = append(line, r*Size+c)
+			r, c = r+dir[0], c+dir[1]
+		}
+		if len(line) > 0 && r >= 0 && r < Size && c >= 0 && c < Size && t.Board[r*Size+c] == player {
+			flipped = append(flipped, line...)
+		}
+	}
+	return flipped
+}
+
+// 取得player可以落子的位置(不含虛手)
+func (t *GameState) ValidMoves(player int) []int {
+	var moves []int
+	for pos := 0; pos < Size*Size; pos++ {
+		if len(t.flips(player, pos)) > 0 {
+			moves = append(moves, pos)
+		}
+	}
+	return moves
+}
+
+// 判斷player是否還有可以落子的位置，找到一個就返回
+func (t *GameState) hasValidMove(player int) bool {
+	for pos := 0; pos < Size*Size; pos++ {
+		if len(t.flips(player, pos)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// 取得目前玩家可執行的動作，無子可下但棋局未結束時只能虛手(實作mcts.Game)
+func (t *GameState) GetLegalMoves() []int {
+	moves := t.ValidMoves(t.Player)
+	if len(moves) > 0 {
+		return moves
+	}
+	if t.hasValidMove(Opponent(t.Player)) {
+		return []int{Pass}
+	}
+	return nil // 雙方都無子可下，棋局已結束
+}
+
+// 由目前玩家執行動作並翻轉被夾住的棋子(實作mcts.Game)
+func (t *GameState) ApplyMove(pos int) {
+	if pos != Pass {
+		// 先找出被夾住的棋子再落子，落子後該位置不再是空格
+		flipped := t.flips(t.Player, pos)
+		t.Board[pos] = t.Player
+		for _, p := range flipped {
+			t.Board[p] = t.Player
+		}
+	}
+	t.LastPlaced = pos
+	t.Player = Opponent(t.Player)
+}
+
+// 判斷目前玩家執行pos是否合法
+func (t *GameState) IsLegalMove(pos int) bool {
+	for _, move := range t.GetLegalMoves() {
+		if move == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// 複製棋況(實作mcts.Game)
+func (t *GameState) Clone() mcts.Game {
+	newState := *t
+	return &newState
+}
+
+// 傳入棋況取得目前換哪位玩家行動(實作mcts.Game)
+func (t *GameState) CurrentPlayer() int {
+	return t.Player
+}
+
+// 計算雙方棋子數量
+func (t *GameState) CountDiscs() (player1, player2 int) {
+	for _, v := range t.Board {
+		if v == Player1 {
+			player1++
+		} else if v == Player2 {
+			player2++
+		}
+	}
+	return player1, player2
+}
+
+// 傳入棋況取得目前結果，雙方都無子可下時棋局結束，棋子多的一方獲勝
+func (t *GameState) GetGameState() GameResult {
+	if t.hasValidMove(Player1) || t.hasValidMove(Player2) {
+		return GameResult{false, None}
+	}
+	p1, p2 := t.CountDiscs()
+	switch {
+	case p1 > p2:
+		return GameResult{true, Player1}
+	case p2 > p1:
+		return GameResult{true, Player2}
+	}
+	return GameResult{true, None}
+}
+
+// 取得棋局是否結束與贏家(實作mcts.Game)
+func (t *GameState) Result() (bool, int) {
+	result := t.GetGameState()
+	return result.IsTerminal, result.Winner
+}
+
+// 將動作轉成棋譜座標(例如d3)，虛手為pass
+func MoveString(pos int) string {
+	if pos == Pass {
+		return "pass"
+	}
+	return fmt.Sprintf("%c%d", 'a'+pos%Size, pos/Size+1)
+}
+
+// 將棋譜座標(例如d3或pass)轉回動作
+func ParseMove(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "pass" {
+		return Pass, nil
+	}
+	if len(s) != 2 || s[0] < 'a' || s[0] >= 'a'+Size || s[1] < '1' || s[1] >= '1'+Size {
+		return 0, fmt.Errorf("無效的座標: %q", s)
+	}
+	return int(s[1]-'1')*Size + int(s[0]-'a'), nil
+}
+
+// 畫出棋況結果圖
+func (state GameState) DrawTable() string {
+	symbols := []rune{'.', 'X', 'O'}
+	var sb strings.Builder
+	sb.WriteString("  a b c d e f g h\n")
+	for row := 0; row < Size; row++ {
+		sb.WriteString(fmt.Sprintf("%d", row+1))
+		for col := 0; col < Size; col++ {
+			sb.WriteRune(' ')
+			sb.WriteRune(symbols[state.Board[row*Size+col]])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
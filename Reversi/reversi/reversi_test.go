@@ -0,0 +1,83 @@
+package reversi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 從state往下depth步的葉節點數，虛手也算一步
+func perft(state *GameState, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for _, move := range state.GetLegalMoves() {
+		child := *state
+		child.ApplyMove(move)
+		nodes += perft(&child, depth-1)
+	}
+	return nodes
+}
+
+func TestPerft(t *testing.T) {
+	want := []int{1, 4, 12, 56, 244, 1396}
+	for depth, nodes := range want {
+		if got := perft(New(), depth); got != nodes {
+			t.Errorf("perft(%d) = %d，預期為 %d", depth, got, nodes)
+		}
+	}
+}
+
+func TestPassWhenOnlyOpponentCanMove(t *testing.T) {
+	// a1白、b1黑，輪到黑：黑沒有能夾住白子的位置，白可以下c1夾住b1
+	state := &GameState{Player: Player1, LastPlaced: -1}
+	state.Board[0] = Player2
+	state.Board[1] = Player1
+	if moves := state.GetLegalMoves(); !reflect.DeepEqual(moves, []int{Pass}) {
+		t.Fatalf("只能虛手時的合法動作為 %v，預期為 [Pass]", moves)
+	}
+	if terminal, _ := state.Result(); terminal {
+		t.Fatal("白還能下，棋局不應該結束")
+	}
+	state.ApplyMove(Pass)
+	if state.Player != Player2 || state.LastPlaced != Pass {
+		t.Fatalf("虛手後應該換白行動: %+v", state)
+	}
+	if moves := state.GetLegalMoves(); !reflect.DeepEqual(moves, []int{2}) {
+		t.Fatalf("白的合法動作為 %v，預期為 [2]", moves)
+	}
+	state.ApplyMove(2)
+	if state.Board[1] != Player2 {
+		t.Error("白下c1後b1應該被翻成白子")
+	}
+}
+
+func TestResultWhenNeitherCanMove(t *testing.T) {
+	tests := []struct {
+		name         string
+		discs        map[int]int
+		winner       int
+		black, white int
+	}{
+		{"黑子較多", map[int]int{0: Player1, 1: Player1, 2: Player1, 63: Player2}, Player1, 3, 1},
+		{"白子較多", map[int]int{0: Player1, 62: Player2, 63: Player2}, Player2, 1, 2},
+		{"子數相同", map[int]int{0: Player1, 63: Player2}, None, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &GameState{Player: Player1, LastPlaced: -1}
+			for pos, player := range test.discs {
+				state.Board[pos] = player
+			}
+			if moves := state.GetLegalMoves(); moves != nil {
+				t.Fatalf("雙方都無子可下時合法動作應該為nil，實際為 %v", moves)
+			}
+			if terminal, winner := state.Result(); !terminal || winner != test.winner {
+				t.Errorf("Result() = (%v, %d)，預期為 (true, %d)", terminal, winner, test.winner)
+			}
+			if black, white := state.CountDiscs(); black != test.black || white != test.white {
+				t.Errorf("CountDiscs() = (%d, %d)，預期為 (%d, %d)", black, white, test.black, test.white)
+			}
+		})
+	}
+}
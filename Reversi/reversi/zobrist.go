@@ -0,0 +1,33 @@
+package reversi
+
+import (
+	"math/rand"
+
+	mcts "mcts/mcts"
+)
+
+// Zobrist hashing：每個位置的每種棋子各有一個固定的隨機值，局面的雜湊值是盤面上所有棋子對應值的XOR
+// 虛手會讓相同盤面輪到不同玩家，所以輪到白子時再XOR一個值
+var zobristKeys, zobristPlayer2 = func() (keys [Size * Size][3]uint64, player2 uint64) {
+	rng := rand.New(rand.NewSource(64))
+	for pos := range keys {
+		for player := Player1; player <= Player2; player++ {
+			keys[pos][player] = rng.Uint64()
+		}
+	}
+	return keys, rng.Uint64()
+}()
+
+var _ mcts.Hasher = (*GameState)(nil)
+
+// 局面的雜湊值(實作mcts.Hasher)
+func (t *GameState) Hash() uint64 {
+	var hash uint64
+	for pos, player := range t.Board {
+		hash ^= zobristKeys[pos][player]
+	}
+	if t.Player == Player2 {
+		hash ^= zobristPlayer2
+	}
+	return hash
+}
@@ -0,0 +1,166 @@
+package arena
+
+// 對戰場：讓任兩個玩家對戰N局(輪流先手)，統計勝/和/負、信賴區間與各開局的結果
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mcts "mcts/mcts"
+)
+
+// 參與對戰的玩家
+type Player interface {
+	Name() string
+	ChooseMove(game mcts.Game) int // 傳入的是遊戲的複本，玩家可以任意修改
+}
+
+// 需要知道每一步棋的玩家可以實作此介面(例如重複使用搜尋樹的MCTS玩家)，對戰場會在開局與每一步棋之後通知
+type MoveObserver interface {
+	NewGame(game mcts.Game)
+	ObserveMove(move int)
+}
+
+// 對戰設定
+type Options struct {
+	Games        int              // 對戰局數，雙方輪流先手
+	OpeningPlies int              // 以前幾手當作開局分類，0代表不統計開局
+	MoveString   func(int) string // 開局中動作的顯示方式，nil時直接顯示數字
+	OnGame       func(GameRecord) // 每局結束時呼叫(可為nil)，可用來顯示進度
+}
+
+// 一局的紀錄
+type GameRecord struct {
+	Index     int   // 第幾局(從0開始)
+	AFirst    bool  // 是否由玩家A先手
+	Moves     []int // 整局的動作
+	Winner    int   // 遊戲回報的贏家(0為平手)
+	Outcome   Outcome
+	OpeningID string
+}
+
+// 以玩家A的角度表示的勝負
+type Outcome int
+
+const (
+	Loss Outcome = iota - 1
+	Draw
+	Win
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Win:
+		return "勝"
+	case Loss:
+		return "負"
+	}
+	return "和"
+}
+
+// 讓玩家a與玩家b對戰，偶數局a先手，奇數局b先手，結果以a的角度統計
+func Play(newGame func() mcts.Game, a, b Player, options Options) (*Result, error) {
+	result := newResult(a.Name(), b.Name())
+	for i := 0; i < options.Games; i++ {
+		aFirst := i%2 == 0
+		record, err := playGame(newGame(), a, b, aFirst)
+		if err != nil {
+			return result, fmt.Errorf("第%d局: %w", i+1, err)
+		}
+		record.Index = i
+		record.OpeningID = openingID(record.Moves, options.OpeningPlies, options.MoveString)
+		result.add(record, options.OpeningPlies > 0)
+		if options.OnGame != nil {
+			options.OnGame(record)
+		}
+	}
+	return result, nil
+}
+
+// 進行一局對戰，先手的玩家為遊戲中的CurrentPlayer()
+func playGame(game mcts.Game, a, b Player, aFirst bool) (GameRecord, error) {
+	record := GameRecord{AFirst: aFirst}
+	first := game.CurrentPlayer()
+	observers := observersOf(a, b)
+	for _, observer := range observers {
+		observer.NewGame(game.Clone())
+	}
+	for {
+		terminal, winner := game.Result()
+		if terminal {
+			record.Winner = winner
+			break
+		}
+
+		player := a
+		if (game.CurrentPlayer() == first) != aFirst {
+			player = b
+		}
+		move := player.ChooseMove(game.Clone())
+		if !isLegal(game, move) {
+			return record, fmt.Errorf("%s 下了不合法的動作 %d", player.Name(), move)
+		}
+		game.ApplyMove(move)
+		record.Moves = append(record.Moves, move)
+		for _, observer := range observers {
+			observer.ObserveMove(move)
+		}
+	}
+
+	// 先手玩家的編號為first，換算成玩家A的勝負(雙人遊戲玩家編號為1與2)
+	aPlayer := first
+	if !aFirst {
+		aPlayer = 3 - first
+	}
+	switch record.Winner {
+	case 0:
+		record.Outcome = Draw
+	case aPlayer:
+		record.Outcome = Win
+	default:
+		record.Outcome = Loss
+	}
+	return record, nil
+}
+
+// 取得有實作MoveObserver的玩家，同一個玩家下雙方時只通知一次
+func observersOf(a, b Player) []MoveObserver {
+	var observers []MoveObserver
+	if observer, ok := a.(MoveObserver); ok {
+		observers = append(observers, observer)
+	}
+	if observer, ok := b.(MoveObserver); ok && b != a {
+		observers = append(observers, observer)
+	}
+	return observers
+}
+
+// 判斷動作是否合法
+func isLegal(game mcts.Game, move int) bool {
+	for _, m := range game.GetLegalMoves() {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+// 以前plies手組成開局代號，例如"4 0"
+func openingID(moves []int, plies int, moveString func(int) string) string {
+	if plies <= 0 {
+		return ""
+	}
+	if len(moves) > plies {
+		moves = moves[:plies]
+	}
+	names := make([]string, len(moves))
+	for i, move := range moves {
+		if moveString != nil {
+			names[i] = moveString(move)
+		} else {
+			names[i] = strconv.Itoa(move)
+		}
+	}
+	return strings.Join(names, " ")
+}
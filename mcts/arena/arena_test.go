@@ -0,0 +1,70 @@
+package arena
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+)
+
+// 依固定的優先順序下第一個合法位置的玩家
+type orderedPlayer struct {
+	name  string
+	order []int
+}
+
+func (p orderedPlayer) Name() string {
+	return p.name
+}
+
+func (p orderedPlayer) ChooseMove(game mcts.Game) int {
+	legal := game.GetLegalMoves()
+	for _, move := range p.order {
+		for _, m := range legal {
+			if m == move {
+				return move
+			}
+		}
+	}
+	return legal[0]
+}
+
+// 兩局的開局都是"4 0"，但一局是A下4、另一局是B下4，要分成兩筆統計
+func TestOpeningsAreSplitBySide(t *testing.T) {
+	order := []int{4, 0, 1, 2, 3, 5, 6, 7, 8}
+	a, b := orderedPlayer{"a", order}, orderedPlayer{"b", order}
+	result, err := Play(func() mcts.Game { return tictactoe.New() }, a, b, Options{Games: 4, OpeningPlies: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Openings) != 2 {
+		t.Fatalf("開局統計有%d筆，預期A先手與A後手各一筆: %+v", len(result.Openings), result.Openings)
+	}
+	for i, aFirst := range []bool{true, false} {
+		opening := result.Openings[i]
+		if opening.Opening != "4 0" || opening.AFirst != aFirst || opening.Games != 2 {
+			t.Errorf("第%d筆開局統計為 %+v，預期為 4 0 AFirst=%v 共2局", i, opening, aFirst)
+		}
+	}
+	if opening := result.Openings[0]; opening.Record != result.AsFirst {
+		t.Errorf("A先手的開局統計 %v 應該等於A先手的總計 %v", opening.Record, result.AsFirst)
+	}
+
+	var text, json bytes.Buffer
+	if err := result.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"A先手 4 0", "A後手 4 0"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("文字結果沒有包含%q:\n%s", want, text.String())
+		}
+	}
+	if err := result.WriteJSON(&json); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(json.String(), `"aFirst": true`) || !strings.Contains(json.String(), `"aFirst": false`) {
+		t.Errorf("json結果沒有標示開局的先後手:\n%s", json.String())
+	}
+}
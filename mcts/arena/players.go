@@ -0,0 +1,156 @@
+package arena
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	mcts "mcts/mcts"
+)
+
+// 隨機玩家，從合法動作中隨機選一個
+type RandomPlayer struct {
+	Rand *rand.Rand // nil時使用共用的亂數來源(第一次使用時以目前時間為種子)
+}
+
+func (p RandomPlayer) Name() string {
+	return "random"
+}
+
+func (p RandomPlayer) ChooseMove(game mcts.Game) int {
+	moves := game.GetLegalMoves()
+	return moves[intn(p.Rand, len(moves))]
+}
+
+// MCTSPlayer沒有設定預算時每步的迭代次數
+const DefaultIterations = 1000
+
+// 使用蒙地卡羅樹搜尋的玩家，Duration大於0時以時間為預算，否則以迭代次數為預算
+// 兩者都沒有設定時(包含零值)每步迭代DefaultIterations次
+type MCTSPlayer struct {
+	Iterations int
+	Duration   time.Duration
+	Rollout    mcts.RolloutPolicy   // 模擬階段的策略，nil時為均勻隨機
+	Selection  mcts.SelectionPolicy // 選擇階段的策略，nil時為UCT
+	Rand       *rand.Rand           // 搜尋的亂數來源，nil時每次搜尋以目前時間為種子
+	Label      string               // 顯示名稱，空字串時依預算產生
+}
+
+func (p MCTSPlayer) Name() string {
+	if p.Label != "" {
+		return p.Label
+	}
+	if p.Duration > 0 {
+		return fmt.Sprintf("mcts(%v)", p.Duration)
+	}
+	return fmt.Sprintf("mcts(%d)", p.iterations())
+}
+
+// 每步的迭代次數，沒有設定時為DefaultIterations
+func (p MCTSPlayer) iterations() int {
+	if p.Iterations <= 0 {
+		return DefaultIterations
+	}
+	return p.Iterations
+}
+
+func (p MCTSPlayer) ChooseMove(game mcts.Game) int {
+	options := mcts.Options{Rollout: p.Rollout, Selection: p.Selection, Rand: p.Rand}
+	if p.Duration > 0 {
+		options.Duration = p.Duration
+	} else {
+		options.MaxIterations = p.iterations()
+	}
+	move, _ := mcts.Search(context.Background(), game, options)
+	return move
+}
+
+// 在整局中重複使用搜尋樹的MCTS玩家，對戰場會透過MoveObserver通知每一步棋
+type ReusingMCTSPlayer struct {
+	Options  mcts.Options
+	searcher *mcts.Searcher
+}
+
+func NewReusingMCTSPlayer(options mcts.Options) *ReusingMCTSPlayer {
+	return &ReusingMCTSPlayer{Options: options}
+}
+
+func (p *ReusingMCTSPlayer) Name() string {
+	if p.Options.Duration > 0 {
+		return fmt.Sprintf("mcts-reuse(%v)", p.Options.Duration)
+	}
+	return fmt.Sprintf("mcts-reuse(%d)", p.Options.MaxIterations)
+}
+
+func (p *ReusingMCTSPlayer) NewGame(game mcts.Game) {
+	p.searcher = mcts.NewSearcher(game, p.Options)
+}
+
+func (p *ReusingMCTSPlayer) ObserveMove(move int) {
+	p.searcher.Advance(move)
+}
+
+func (p *ReusingMCTSPlayer) ChooseMove(game mcts.Game) int {
+	if p.searcher == nil { // 沒有透過對戰場開局時，從目前棋局開始建樹
+		p.NewGame(game)
+	}
+	move, _ := p.searcher.Search(context.Background())
+	return move
+}
+
+// 真人玩家，從In讀取動作並在Out顯示棋盤
+type HumanPlayer struct {
+	In     *bufio.Reader
+	Out    io.Writer
+	Render func(game mcts.Game) string     // 顯示棋盤
+	Parse  func(input string) (int, error) // 將輸入轉成動作
+}
+
+func (p HumanPlayer) Name() string {
+	return "human"
+}
+
+func (p HumanPlayer) ChooseMove(game mcts.Game) int {
+	for {
+		fmt.Fprintln(p.Out, p.Render(game))
+		fmt.Fprintln(p.Out, "請輸入你的動作:")
+		line, err := p.In.ReadString('\n')
+		if err != nil && line == "" {
+			// 沒有輸入可讀時返回不合法的動作，由對戰場回報錯誤
+			return -1
+		}
+		move, err := p.Parse(strings.TrimSpace(line))
+		if err != nil {
+			fmt.Fprintln(p.Out, "輸入有誤，請重新輸入:", err)
+			continue
+		}
+		if !isLegal(game, move) {
+			fmt.Fprintln(p.Out, "不合法的動作，請重新輸入")
+			continue
+		}
+		return move
+	}
+}
+
+var (
+	sharedRandMu sync.Mutex
+	sharedRand   *rand.Rand // 玩家沒有指定Rand時共用的亂數來源，第一次使用時以目前時間為種子
+)
+
+// 從rng取[0,n)的亂數，rng為nil時使用共用的亂數來源(可以在多個goroutine中同時呼叫)
+func intn(rng *rand.Rand, n int) int {
+	if rng != nil {
+		return rng.Intn(n)
+	}
+	sharedRandMu.Lock()
+	defer sharedRandMu.Unlock()
+	if sharedRand == nil {
+		sharedRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return sharedRand.Intn(n)
+}
@@ -0,0 +1,31 @@
+package arena
+
+import (
+	"testing"
+
+	tictactoe "mcts/tictactoe"
+)
+
+func TestRandomPlayerZeroValue(t *testing.T) {
+	game := tictactoe.New()
+	for _, pos := range []int{0, 1, 2, 4, 3, 5, 7, 6} {
+		game.Play(pos)
+	}
+	if move := (RandomPlayer{}).ChooseMove(game); move != 8 {
+		t.Errorf("只剩位置8可以下，實際選擇%d", move)
+	}
+}
+
+func TestMCTSPlayerZeroValue(t *testing.T) {
+	game := tictactoe.New()
+	for _, pos := range []int{0, 3, 1, 4} { // 玩家1下2就連成第一列
+		game.Play(pos)
+	}
+	player := MCTSPlayer{}
+	if name := player.Name(); name != "mcts(1000)" {
+		t.Errorf("零值的名稱為%q，預期為mcts(1000)", name)
+	}
+	if move := player.ChooseMove(game); move != 2 {
+		t.Errorf("零值的MCTS玩家選擇%d，預期選擇致勝的2", move)
+	}
+}
@@ -0,0 +1,182 @@
+package arena
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// 95%信賴區間使用的z值
+const z95 = 1.959963984540054
+
+// 勝/和/負的統計(以玩家A的角度)
+type Record struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+}
+
+// 記錄一局的結果
+func (r *Record) add(outcome Outcome) {
+	r.Games++
+	switch outcome {
+	case Win:
+		r.Wins++
+	case Draw:
+		r.Draws++
+	case Loss:
+		r.Losses++
+	}
+}
+
+// 得分率，勝1分和0.5分
+func (r Record) Score() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + 0.5*float64(r.Draws)) / float64(r.Games)
+}
+
+// 比率與其95%信賴區間
+type Rate struct {
+	Value float64 `json:"value"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+}
+
+// 以Wilson score interval計算比率的95%信賴區間，局數少或比率接近0/1時比常態近似穩定
+func WilsonInterval(successes, n int) Rate {
+	if n == 0 {
+		return Rate{}
+	}
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	denominator := 1 + z95*z95/nf
+	center := (p + z95*z95/(2*nf)) / denominator
+	margin := z95 * math.Sqrt(p*(1-p)/nf+z95*z95/(4*nf*nf)) / denominator
+	return Rate{Value: p, Low: math.Max(0, center-margin), High: math.Min(1, center+margin)}
+}
+
+// 開局的統計，同樣的開局在A先手與A後手時是不同的局面，分開統計
+type OpeningRecord struct {
+	Opening string `json:"opening"`
+	AFirst  bool   `json:"aFirst"` // 是否為A先手的局
+	Record
+}
+
+// 開局統計的key
+type openingKey struct {
+	id     string
+	aFirst bool
+}
+
+// 對戰結果，全部以玩家A的角度統計
+type Result struct {
+	PlayerA  string          `json:"playerA"`
+	PlayerB  string          `json:"playerB"`
+	Total    Record          `json:"total"`
+	WinRate  Rate            `json:"winRate"`
+	DrawRate Rate            `json:"drawRate"`
+	LossRate Rate            `json:"lossRate"`
+	AsFirst  Record          `json:"asFirst"`  // A先手的局
+	AsSecond Record          `json:"asSecond"` // A後手的局
+	Openings []OpeningRecord `json:"openings,omitempty"`
+
+	openings map[openingKey]*Record
+}
+
+func newResult(a, b string) *Result {
+	return &Result{
+		PlayerA:  a,
+		PlayerB:  b,
+		openings: make(map[openingKey]*Record),
+	}
+}
+
+// 加入一局的結果並更新信賴區間與開局統計
+func (r *Result) add(record GameRecord, trackOpenings bool) {
+	r.Total.add(record.Outcome)
+	if record.AFirst {
+		r.AsFirst.add(record.Outcome)
+	} else {
+		r.AsSecond.add(record.Outcome)
+	}
+	r.WinRate = WilsonInterval(r.Total.Wins, r.Total.Games)
+	r.DrawRate = WilsonInterval(r.Total.Draws, r.Total.Games)
+	r.LossRate = WilsonInterval(r.Total.Losses, r.Total.Games)
+
+	if !trackOpenings {
+		return
+	}
+	key := openingKey{id: record.OpeningID, aFirst: record.AFirst}
+	opening, ok := r.openings[key]
+	if !ok {
+		opening = &Record{}
+		r.openings[key] = opening
+	}
+	opening.add(record.Outcome)
+
+	// 依局數由多到少排序，局數相同時依開局代號排序，代號也相同時A先手的在前
+	r.Openings = r.Openings[:0]
+	for key, rec := range r.openings {
+		r.Openings = append(r.Openings, OpeningRecord{Opening: key.id, AFirst: key.aFirst, Record: *rec})
+	}
+	sort.Slice(r.Openings, func(i, j int) bool {
+		a, b := r.Openings[i], r.Openings[j]
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		if a.Opening != b.Opening {
+			return a.Opening < b.Opening
+		}
+		return a.AFirst && !b.AFirst
+	})
+}
+
+// 以文字格式輸出結果
+func (r *Result) WriteText(w io.Writer) error {
+	t := r.Total
+	lines := []string{
+		fmt.Sprintf("%s vs %s 共%d局", r.PlayerA, r.PlayerB, t.Games),
+		fmt.Sprintf("  %s 勝%d 和%d 負%d 得分率%.1f%%", r.PlayerA, t.Wins, t.Draws, t.Losses, t.Score()*100),
+		fmt.Sprintf("  勝率 %s", r.WinRate),
+		fmt.Sprintf("  和率 %s", r.DrawRate),
+		fmt.Sprintf("  負率 %s", r.LossRate),
+		fmt.Sprintf("  先手 %s", r.AsFirst),
+		fmt.Sprintf("  後手 %s", r.AsSecond),
+	}
+	if len(r.Openings) > 0 {
+		lines = append(lines, "  開局:")
+		for _, opening := range r.Openings {
+			side := "A後手"
+			if opening.AFirst {
+				side = "A先手"
+			}
+			lines = append(lines, fmt.Sprintf("    %s %-12s %s", side, opening.Opening, opening.Record))
+		}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 以json格式輸出結果
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+func (r Record) String() string {
+	return fmt.Sprintf("%d局 勝%d 和%d 負%d 得分率%.1f%%", r.Games, r.Wins, r.Draws, r.Losses, r.Score()*100)
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%.1f%% (95%%信賴區間 %.1f%% ~ %.1f%%)", r.Value*100, r.Low*100, r.High*100)
+}
@@ -0,0 +1,69 @@
+// 測試與比較mcts.Game實作用的共用工具，例如驗證同一個遊戲的兩種表示法行為一致
+package gametest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	mcts "mcts/mcts"
+)
+
+// 以相同的隨機動作同時下a與b直到棋局結束，每一步比較目前玩家、結果與未結束時的合法動作，
+// 兩者都實作mcts.Hasher時也比較雜湊值；check不為nil時每一步另外呼叫(ply為已下的步數)，用來比較遊戲特有的狀態
+func CompareRandomGame(rng *rand.Rand, a, b mcts.Game, check func(ply int, a, b mcts.Game) error) error {
+	hashA, hasHashA := a.(mcts.Hasher)
+	hashB, hasHashB := b.(mcts.Hasher)
+	for ply := 0; ; ply++ {
+		if a.CurrentPlayer() != b.CurrentPlayer() {
+			return fmt.Errorf("第%d手目前玩家不同: %d / %d", ply, a.CurrentPlayer(), b.CurrentPlayer())
+		}
+		if hasHashA && hasHashB && hashA.Hash() != hashB.Hash() {
+			return fmt.Errorf("第%d手雜湊值不同: %x / %x", ply, hashA.Hash(), hashB.Hash())
+		}
+		if check != nil {
+			if err := check(ply, a, b); err != nil {
+				return fmt.Errorf("第%d手: %w", ply, err)
+			}
+		}
+		terminal, winner := a.Result()
+		if t, w := b.Result(); t != terminal || w != winner {
+			return fmt.Errorf("第%d手結果不同: (%v, %d) / (%v, %d)", ply, terminal, winner, t, w)
+		}
+		if terminal {
+			return nil
+		}
+		moves := a.GetLegalMoves()
+		if other := b.GetLegalMoves(); !reflect.DeepEqual(moves, other) {
+			return fmt.Errorf("第%d手合法動作不同: %v / %v", ply, moves, other)
+		}
+		move := moves[rng.Intn(len(moves))]
+		a.ApplyMove(move)
+		b.ApplyMove(move)
+	}
+}
+
+// 測量從newGame開始均勻隨機下到棋局結束的速度(與MCTS模擬階段相同的操作)
+func BenchmarkRandomPlayouts(b *testing.B, newGame func() mcts.Game) {
+	rng := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		game := newGame()
+		for terminal, _ := game.Result(); !terminal; terminal, _ = game.Result() {
+			moves := game.GetLegalMoves()
+			game.ApplyMove(moves[rng.Intn(len(moves))])
+		}
+	}
+}
+
+// 測量從newGame開始以固定種子搜尋iterations次的速度
+func BenchmarkSearch(b *testing.B, newGame func() mcts.Game, iterations int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := mcts.Search(context.Background(), newGame(), mcts.Options{MaxIterations: iterations, Seed: 1}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
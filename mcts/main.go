@@ -1,41 +1,53 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
 	"time"
 
+	arena "mcts/arena"
 	mcts "mcts/mcts"
 	tictactoe "mcts/tictactoe"
 )
 
 const (
-	playTimes = 1000
-	selfPlay  = false
+	playTimes  = 1000
+	selfPlay   = false
+	exportTree = false // true時搜尋一次空棋盤並把搜尋樹輸出成tree.dot與tree.json
+	seed       = 0     // 亂數種子，0時使用目前時間，固定種子可以重現自我對弈與搜尋結果
 )
 
 func main() {
-	winTimes := 0
-	tieTimes := 0
-	rand.Seed(time.Now().UnixNano())
+	if exportTree {
+		exportSearchTree()
+		return
+	}
 	// aa := mcts.MonteCarloTreeSearch(game, 10)
 	// fmt.Println(aa)
 	// return
 	if selfPlay {
-		for i := 0; i < playTimes; i++ {
-			game := aiSelfPlay()
-			winner := game.GetGameState().Winner
-			if winner == tictactoe.None {
-				tieTimes++
-				fmt.Println("平手!")
-			} else {
-				if winner == tictactoe.Player1 {
-					winTimes++
+		newGame := func() mcts.Game { return tictactoe.New() }
+		rng := newRand()
+		result, err := arena.Play(newGame, arena.NewReusingMCTSPlayer(mcts.Options{MaxIterations: 1000, Rand: rng}), arena.MCTSPlayer{Iterations: 1, Rand: rng}, arena.Options{
+			Games:        playTimes,
+			OpeningPlies: 2,
+			OnGame: func(record arena.GameRecord) {
+				order := "後手"
+				if record.AFirst {
+					order = "先手"
 				}
-				fmt.Printf("玩家 %d 獲勝!\n", winner)
-			}
+				fmt.Printf("第%d局 玩家1(%s) %s\n", record.Index+1, order, record.Outcome)
+			},
+		})
+		if err != nil {
+			fmt.Println("對戰失敗:", err)
+			return
 		}
-		fmt.Printf("在%d局對戰中 玩家1的勝率為%.1f%% 平手率為%.1f%%", playTimes, float64(winTimes)/float64(playTimes)*100, float64(tieTimes)/float64(playTimes)*100)
+		result.WriteText(os.Stdout)
 	} else {
 		game := playWithAI()
 		winner := game.GetGameState().Winner
@@ -53,36 +65,75 @@ func main() {
 
 }
 
-func aiSelfPlay() *tictactoe.GameState {
-	game := tictactoe.New()
-	for !game.GetGameState().IsTerminal {
-		if game.CurrentPlayer() == tictactoe.Player1 { // 玩家1行動
-			pos := mcts.MonteCarloTreeSearch(game, 1000)
-			game.Board[pos] = tictactoe.Player1
-			// fmt.Println(game.DrawTable())
-			// fmt.Println("玩家1 放置旗子在位置", pos)
-		} else { //玩家2行動
-			pos := mcts.MonteCarloTreeSearch(game, 1)
-			game.Board[pos] = tictactoe.Player2
-			// fmt.Println(game.DrawTable())
-			// fmt.Println("玩家2 放置旗子在位置", pos)
-		}
-		//time.Sleep(100 * time.Millisecond)
+// 以seed建立亂數來源，seed為0時使用目前時間
+func newRand() *rand.Rand {
+	if seed != 0 {
+		return rand.New(rand.NewSource(seed))
 	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
 
-	return game
+// 從空棋盤搜尋一次，輸出只保留前3個子節點、深度3以內的搜尋樹
+func exportSearchTree() {
+	searcher := mcts.NewSearcher(tictactoe.New(), mcts.Options{MaxIterations: 1000, Rand: newRand()})
+	searcher.Search(context.Background())
+	options := mcts.ExportOptions{
+		MaxDepth: 3,
+		TopK:     3,
+		Board:    func(game mcts.Game) string { return game.(*tictactoe.GameState).DrawTable() },
+	}
+	for filename, write := range map[string]func(w io.Writer, options mcts.ExportOptions) error{
+		"tree.dot":  searcher.Root().WriteDOT,
+		"tree.json": searcher.Root().WriteJSON,
+	} {
+		file, err := os.Create(filename)
+		if err != nil {
+			fmt.Println("建立檔案失敗:", err)
+			return
+		}
+		err = write(file, options)
+		file.Close()
+		if err != nil {
+			fmt.Println("輸出搜尋樹失敗:", err)
+			return
+		}
+		fmt.Println("已輸出搜尋樹:", filename)
+	}
 }
+
 func playWithAI() *tictactoe.GameState {
 	game := tictactoe.New()
+	// AI的搜尋樹在整局中重複使用，每一步棋(包含玩家的)都要讓搜尋器跟著前進
+	searcher := mcts.NewSearcher(game, mcts.Options{MaxIterations: 1000, Solver: true, Transpositions: true, Rand: newRand()})
 	for !game.GetGameState().IsTerminal {
 		if game.CurrentPlayer() == tictactoe.Player1 { // 玩家1行動
-			pos := getPlayerInput(game) // 自行實現此函數，根據玩家輸入選擇行動
-			game.Board[pos] = tictactoe.Player1
+			pos := getPlayerInput(game) // 根據玩家輸入選擇位置
+			if err := game.PlayMove(tictactoe.Player1, pos); err != nil {
+				fmt.Println("無法放置棋子:", err)
+				continue
+			}
+			if err := searcher.Advance(pos); err != nil {
+				fmt.Println("AI的搜尋樹無法跟著前進:", err)
+				return game
+			}
 			fmt.Println(game.DrawTable())
 			fmt.Println("玩家 放置旗子在位置", pos)
 		} else { //玩家2行動
-			pos := mcts.MonteCarloTreeSearch(game, 1000)
-			game.Board[pos] = tictactoe.Player2
+			result, err := searcher.Analyze(context.Background())
+			if err != nil {
+				fmt.Println("AI搜尋失敗:", err)
+				return game
+			}
+			fmt.Println(result) // 印出每個動作的訪問次數與價值，方便了解AI的信心程度
+			pos := result.Move
+			if err := game.PlayMove(tictactoe.Player2, pos); err != nil {
+				fmt.Println("AI的動作不合法:", err)
+				return game
+			}
+			if err := searcher.Advance(pos); err != nil {
+				fmt.Println("AI的搜尋樹無法跟著前進:", err)
+				return game
+			}
 			fmt.Println(game.DrawTable())
 			fmt.Println("AI 放置旗子在位置", pos)
 		}
@@ -91,7 +142,7 @@ func playWithAI() *tictactoe.GameState {
 	return game
 }
 
-// 取得玩家輸入
+// 取得玩家輸入，返回合法的位置(不會放置棋子)
 func getPlayerInput(state *tictactoe.GameState) int {
 	var playerInput int
 	for {
@@ -109,15 +160,17 @@ func getPlayerInput(state *tictactoe.GameState) int {
 		fmt.Scanf("%c", &newline)
 
 		// 檢查玩家輸入是否合法
-		if playerInput < 0 || playerInput > 8 {
+		err = state.CheckMove(tictactoe.Player1, playerInput)
+		switch {
+		case errors.Is(err, tictactoe.ErrOutOfRange):
 			fmt.Println("輸入範圍有誤，請輸入0-8之間的數字")
 			continue
-		}
-
-		// 檢查選擇的位置是否已被佔用
-		if state.Board[playerInput] != 0 {
+		case errors.Is(err, tictactoe.ErrOccupied):
 			fmt.Println("該位置已被佔用，請選擇其他位置")
 			continue
+		case err != nil:
+			fmt.Println("無法放置棋子:", err)
+			continue
 		}
 		break
 	}
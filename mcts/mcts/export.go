@@ -0,0 +1,153 @@
+package mcts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// 匯出搜尋樹的設定，0代表該項不限制
+type ExportOptions struct {
+	MaxDepth  int                    // 最多匯出的深度(根節點為0)
+	TopK      int                    // 每個節點最多匯出訪問次數最多的前K個子節點
+	Selection SelectionPolicy        // 計算子節點分數的策略，nil時為NewUCT(math.Sqrt2)
+	Board     func(game Game) string // 節點棋盤的文字，nil時使用遊戲的String方法(沒有時不輸出棋盤)
+}
+
+// 匯出的節點資料
+type ExportedNode struct {
+	ID       int             `json:"id"`               // 節點編號，置換表共用的節點在不同位置有相同的編號
+	Move     int             `json:"move"`             // 走到此節點的動作，根節點為-1
+	Player   int             `json:"player"`           // 執行move的玩家，根節點為0
+	Visits   int             `json:"visits"`           // 訪問次數
+	Wins     float64         `json:"wins"`             // 累積獎勵(以player的角度)
+	UCT      *float64        `json:"uct,omitempty"`    // 以父節點計算的選擇分數，根節點沒有
+	Board    string          `json:"board,omitempty"`  // 棋盤
+	Pruned   int             `json:"pruned,omitempty"` // 因TopK或MaxDepth而省略的子節點數
+	Proven   *int            `json:"proven,omitempty"` // MCTS-Solver已證明時的贏家(0代表和局)
+	Shared   bool            `json:"shared,omitempty"` // 置換表共用且已在別處輸出過的節點，不再輸出子節點
+	Children []*ExportedNode `json:"children,omitempty"`
+}
+
+// 取得第一棵搜尋樹的根節點(根平行化時每個goroutine各有一棵樹)
+func (s *Searcher) Root() *TreeNode {
+	return s.roots[0]
+}
+
+// 依設定把以t為根的子樹轉成匯出用的資料
+func (t *TreeNode) Export(options ExportOptions) *ExportedNode {
+	if options.Selection == nil {
+		options.Selection = NewUCT(math.Sqrt2)
+	}
+	if options.Board == nil {
+		options.Board = func(game Game) string {
+			if s, ok := game.(fmt.Stringer); ok {
+				return s.String()
+			}
+			return ""
+		}
+	}
+	e := &exporter{options: options, ids: make(map[*TreeNode]int)}
+	return e.export(t, -1, 0)
+}
+
+// 匯出時記錄已輸出過的節點
+type exporter struct {
+	options ExportOptions
+	ids     map[*TreeNode]int
+}
+
+func (e *exporter) export(t *TreeNode, move int, depth int) *ExportedNode {
+	options := e.options
+	stats := t.nodeStats()
+	node := &ExportedNode{
+		Move:   move,
+		Player: t.player,
+		Visits: int(stats.Visits),
+		Wins:   stats.Wins,
+		Board:  options.Board(t.state),
+	}
+	if proven, winner := t.provenResult(); proven {
+		node.Proven = &winner
+	}
+	if id, ok := e.ids[t]; ok {
+		node.ID, node.Shared = id, true
+		return node
+	}
+	node.ID = len(e.ids)
+	e.ids[t] = node.ID
+
+	t.mu.Lock()
+	children := append([]edge(nil), t.children...)
+	t.mu.Unlock()
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].node.nodeStats().Visits > children[j].node.nodeStats().Visits
+	})
+	kept := children
+	if options.MaxDepth > 0 && depth >= options.MaxDepth {
+		kept = nil
+	} else if options.TopK > 0 && len(kept) > options.TopK {
+		kept = kept[:options.TopK]
+	}
+	node.Pruned = len(children) - len(kept)
+
+	for _, child := range kept {
+		exported := e.export(child.node, child.move, depth+1)
+		childStats := child.node.nodeStats()
+		childStats.Prior = child.prior
+		uct := options.Selection.Score(stats, childStats)
+		exported.UCT = &uct
+		node.Children = append(node.Children, exported)
+	}
+	return node
+}
+
+// 以JSON格式輸出子樹
+func (t *TreeNode) WriteJSON(w io.Writer, options ExportOptions) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t.Export(options))
+}
+
+// 以Graphviz DOT格式輸出子樹，可用 dot -Tsvg tree.dot -o tree.svg 產生圖片
+// 置換表共用的節點只輸出一次，有多條邊指向它；邊上標示動作與UCT分數
+func (t *TreeNode) WriteDOT(w io.Writer, options ExportOptions) error {
+	var sb strings.Builder
+	sb.WriteString("digraph mcts {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	var write func(node *ExportedNode)
+	write = func(node *ExportedNode) {
+		label := fmt.Sprintf("N=%d W=%.2f", node.Visits, node.Wins)
+		if node.Proven != nil {
+			label += "\\l已證明: " + provenString(*node.Proven)
+		}
+		if node.Pruned > 0 {
+			label += fmt.Sprintf("\\l(省略%d個子節點)", node.Pruned)
+		}
+		if node.Board != "" {
+			label += "\\l" + dotEscape(node.Board)
+		}
+		fmt.Fprintf(&sb, "  n%d [label=\"%s\\l\"];\n", node.ID, label)
+		for _, child := range node.Children {
+			if !child.Shared {
+				write(child)
+			}
+			fmt.Fprintf(&sb, "  n%d -> n%d [label=\"%d\\nUCT=%.3f\"];\n", node.ID, child.ID, child.Move, *child.UCT)
+		}
+	}
+	write(t.Export(options))
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// 轉換成DOT標籤可用的文字，換行改為靠左對齊的\l
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.TrimRight(s, "\n")
+	return strings.ReplaceAll(s, "\n", "\\l")
+}
@@ -0,0 +1,138 @@
+package mcts
+
+import (
+	"math"
+	"sort"
+)
+
+// 搜尋結束後選擇實際要下的動作的方式
+type FinalMoveRule int
+
+const (
+	MaxVisits   FinalMoveRule = iota // 訪問次數最多的子節點(預設)
+	MaxValue                         // 平均價值最高的子節點
+	RobustMax                        // 訪問次數與平均價值都最高的子節點，兩者不同時選兩者正規化後總和最高的子節點
+	SecureChild                      // 價值下界(平均價值 - 1/sqrt(n))最高的子節點，偏好訪問次數多的穩健選擇
+)
+
+// 勝、和、負時以行動方角度得到的獎勵
+type Rewards struct {
+	Win  float64
+	Draw float64
+	Loss float64
+}
+
+// 預設獎勵，平手算0.1勝
+var DefaultRewards = Rewards{Win: 1, Draw: 0.1, Loss: 0}
+
+// player在贏家為winner(0代表平手)時得到的獎勵
+func (r Rewards) Of(player, winner int) float64 {
+	switch winner {
+	case 0:
+		return r.Draw
+	case player:
+		return r.Win
+	default:
+		return r.Loss
+	}
+}
+
+// 根節點每個動作的搜尋統計，根平行化時為各樹的加總
+type MoveStats struct {
+	Move   int
+	Visits int     // 訪問次數
+	Wins   float64 // 累積獎勵(以執行該動作的玩家角度)
+	Value  float64 // 平均價值Wins/Visits
+	Proven bool    // MCTS-Solver是否已證明此動作的結果(任一棵樹證明即可)
+	Winner int     // 已證明時的贏家(0代表和局)
+}
+
+// 合併多棵樹根節點子節點的統計，依訪問次數由多到少排序(同次數時依第一次出現的順序)
+func mergeMoveStats(roots []*TreeNode) []MoveStats {
+	index := make(map[int]int)
+	var stats []MoveStats
+	for _, root := range roots {
+		root.mu.Lock()
+		children := root.children
+		root.mu.Unlock()
+		for _, e := range children {
+			n := e.node.nodeStats()
+			i, ok := index[e.move]
+			if !ok {
+				i = len(stats)
+				index[e.move] = i
+				stats = append(stats, MoveStats{Move: e.move})
+			}
+			stats[i].Visits += int(n.Visits)
+			stats[i].Wins += n.Wins
+			if proven, winner := e.node.provenResult(); proven {
+				stats[i].Proven, stats[i].Winner = true, winner
+			}
+		}
+	}
+	for i := range stats {
+		if stats[i].Visits > 0 {
+			stats[i].Value = stats[i].Wins / float64(stats[i].Visits)
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Visits > stats[j].Visits })
+	return stats
+}
+
+// 依規則從統計中選出動作，沒有任何統計時返回-1
+// 有已證明mover獲勝的動作時直接選擇(取訪問次數最多的)，已證明mover落敗的動作只在沒有其他動作時才考慮
+func chooseFinalMove(stats []MoveStats, rule FinalMoveRule, mover int) int {
+	if len(stats) == 0 {
+		return -1
+	}
+	var candidates []MoveStats
+	for _, s := range stats {
+		if s.Proven && s.Winner == mover {
+			return s.Move
+		}
+		if !s.Proven || s.Winner == 0 {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) > 0 {
+		stats = candidates
+	}
+	var score func(s MoveStats) float64
+	switch rule {
+	case MaxValue:
+		score = func(s MoveStats) float64 { return s.Value }
+	case RobustMax:
+		// stats已依訪問次數排序，第一個就是訪問次數最多的
+		maxVisits, maxValue := float64(stats[0].Visits), math.Inf(-1)
+		for _, s := range stats {
+			maxValue = math.Max(maxValue, s.Value)
+		}
+		if stats[0].Value == maxValue {
+			return stats[0].Move
+		}
+		minValue := maxValue
+		for _, s := range stats {
+			minValue = math.Min(minValue, s.Value)
+		}
+		score = func(s MoveStats) float64 {
+			return float64(s.Visits)/maxVisits + (s.Value-minValue)/(maxValue-minValue)
+		}
+	case SecureChild:
+		score = func(s MoveStats) float64 {
+			if s.Visits == 0 {
+				return math.Inf(-1)
+			}
+			return s.Value - 1/math.Sqrt(float64(s.Visits))
+		}
+	default:
+		score = func(s MoveStats) float64 { return float64(s.Visits) }
+	}
+
+	best := 0
+	for i, s := range stats {
+		if score(s) > score(stats[best]) {
+			best = i
+		}
+	}
+	return stats[best].Move
+}
@@ -3,130 +3,321 @@ package mcts
 // MCTS主要有四個階段：選擇(Selection)、擴展(Expansion)、模擬(Rollout)和反向傳播(Backpropagation)
 
 import (
+	"context"
+	"errors"
 	"math"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	tictactoe "mcts/tictactoe"
 )
 
-// 節點資料(目前遊戲狀態、父節點、子節點、勝利次數、訪問次數和未探索位置)
+// 遊戲介面，任何實作此介面的遊戲(井字棋、四子棋、黑白棋...)都可以交給MonteCarloTreeSearch搜尋
+type Game interface {
+	GetLegalMoves() []int                // 取得目前玩家可執行的動作
+	ApplyMove(move int)                  // 由目前玩家執行動作
+	Clone() Game                         // 複製一份遊戲狀態，搜尋時不會修改原本的遊戲
+	CurrentPlayer() int                  // 取得目前換哪位玩家行動
+	Result() (terminal bool, winner int) // 棋局是否結束與贏家(0代表平手)
+}
+
+// 可以計算局面雜湊值的遊戲，啟用置換表(Options.Transpositions)時需要實作
+// 相同局面(含輪到的玩家)必須得到相同的值，不同局面的值要幾乎不會碰撞(例如Zobrist hashing)
+type Hasher interface {
+	Hash() uint64
+}
+
+// 節點資料(目前遊戲狀態、子節點、勝利次數、訪問次數和未探索動作)
+// 訪問次數在往下走時就先加上(同時作為平行搜尋的虛擬損失)，反向傳播時只更新勝利次數
+// 啟用置換表時相同局面只有一個節點，節點可能有多個父節點，所以節點不記錄父節點，反向傳播沿著這次迭代走過的路徑進行
+// mu保護wins、squares、visits、children、unexploredMoves、priors、expanding與證明結果，state、player建立後就不會改變
 type TreeNode struct {
-	state          *tictactoe.GameState
-	parent         *TreeNode
-	children       []*TreeNode
-	wins           float64
-	visits         float64
-	unexploredPosz []int
+	mu              sync.Mutex
+	state           Game
+	children        []edge
+	player          int // 走到此節點的玩家(上個行動的玩家)，根節點為0
+	wins            float64
+	squares         float64 // 獎勵平方的累積(UCB1-Tuned用)
+	visits          float64
+	unexploredMoves []int
+	priors          []float64 // 未探索動作的先驗機率，與unexploredMoves對應，第一次選擇時才計算
+	expanding       int       // 已從unexploredMoves移除但子節點還沒加入children的動作數
+	proven          bool      // MCTS-Solver已證明此節點的結果
+	provenWinner    int       // 已證明時的贏家(0代表和局)
 }
 
-// 傳入目前狀態、玩家、迭代次數取得最佳動作
-func MonteCarloTreeSearch(game *tictactoe.GameState, iterations int) int {
-	rand.Seed(time.Now().UnixNano())
+// 從父節點走到子節點的動作
+type edge struct {
+	move  int
+	prior float64 // move的先驗機率
+	node  *TreeNode
+}
 
-	root := &TreeNode{
-		state:          game,
-		unexploredPosz: game.GetLegalPosz(),
-	}
+// 平行搜尋的方式
+type ParallelMode int
 
-	for i := 0; i < iterations; i++ {
-		node := root.selectNode()
-		winner, finalNode := node.rollout()
-		//fmt.Println("開始反向傳播:", node.state)
-		finalNode.backpropagation(winner)
-	}
+const (
+	RootParallel ParallelMode = iota // 每個goroutine各自建一棵樹，最後依根節點子節點的訪問次數合併
+	TreeParallel                     // 所有goroutine共用一棵樹，以虛擬損失避免同時走同一條路
+)
 
-	bestChild := root.bestChild()
-	return bestChild.state.LastPlaced
+// 搜尋設定，預算任一項達到上限就停止搜尋，0代表該項不限制
+// 指定Rand或Seed且只用MaxIterations或MaxNodes作為預算的單執行緒搜尋，每次執行的結果都相同；
+// 時間預算與平行搜尋會受執行速度與排程影響，無法重現
+type Options struct {
+	MaxIterations  int             // 最多迭代次數(所有goroutine合計)
+	MaxNodes       int             // 樹中最多節點數(含根節點，所有樹合計)
+	Duration       time.Duration   // 搜尋時間上限
+	Workers        int             // 平行搜尋的goroutine數，小於等於1時為單執行緒搜尋
+	Parallel       ParallelMode    // Workers大於1時的平行方式
+	Rollout        RolloutPolicy   // 模擬階段的策略，nil時為均勻隨機
+	Selection      SelectionPolicy // 選擇階段的策略，nil時為NewUCT(math.Sqrt2)
+	FinalMove      FinalMoveRule   // 搜尋結束後選擇動作的方式
+	Rewards        Rewards         // 勝、和、負的獎勵，零值時為DefaultRewards
+	Solver         bool            // 啟用MCTS-Solver，證明勝負後不再模擬，根節點證明後提前停止
+	Rand           *rand.Rand      // 亂數來源，只在呼叫Search的goroutine使用，用來產生各goroutine的亂數來源；nil時以Seed建立
+	Seed           int64           // Rand為nil時的亂數種子，0時使用目前時間
+	Transpositions bool            // 啟用置換表，相同局面共用節點與統計(遊戲需要實作Hasher)
 }
 
-// 選擇(Selection)-選擇最佳UTC值得節點
-func (t *TreeNode) selectNode() *TreeNode {
-	// 目前節點如果是第一次訪問 或是 還有未探索的位置 或是沒有任何子節點 返回目前節點
-	if t.visits == 0 || len(t.unexploredPosz) > 0 || len(t.children) == 0 {
-		return t
-	}
-	var bestChild *TreeNode
-	maxUCT := math.Inf(-1)
-	for _, child := range t.children {
-
-		// UCT計算公式為：UCT = (w / n) + C * sqrt(ln(N) / n)
-		// 前半段的(w / n)代表利用 後半段的C * sqrt(ln(N) / n)代表探索  C探索率，通常是sqrt(2)，可以根據類型調整。C越大會使AI更注重探索，C越小會使AI注重利用
-		uct := 0.0
-		if child.visits != 0 {
-			uct = child.wins/child.visits + math.Sqrt(2*math.Log(t.visits)/child.visits)
-			//fmt.Println("uct=", uct)
-		}
+var (
+	ErrNoBudget    = errors.New("mcts: 沒有設定任何搜尋預算(迭代次數、節點數、時間或context期限)")
+	ErrNoLegalMove = errors.New("mcts: 沒有可執行的動作")
+	ErrGameOver    = errors.New("mcts: 棋局已結束，無法搜尋")
+	ErrNoHash      = errors.New("mcts: 遊戲沒有實作Hasher，無法使用置換表")
+)
 
-		if uct > maxUCT {
-			maxUCT = uct
-			bestChild = child
-		}
+// 傳入目前遊戲、迭代次數取得最佳動作
+func MonteCarloTreeSearch(game Game, iterations int) int {
+	move, _ := Search(context.Background(), game, Options{MaxIterations: iterations})
+	return move
+}
+
+// 在限定時間內搜尋並返回目前找到的最佳動作
+func SearchFor(game Game, duration time.Duration) int {
+	move, _ := Search(context.Background(), game, Options{Duration: duration})
+	return move
+}
+
+// 持續搜尋直到ctx結束或用完options的預算，返回目前找到的最佳動作
+// 預算用完前至少會完成一次迭代，所以ctx已經結束時仍會返回合法的動作
+// 每次呼叫都會建立新的搜尋樹，需要跨回合保留搜尋樹時請使用Searcher
+func Search(ctx context.Context, game Game, options Options) (int, error) {
+	return NewSearcher(game, options).Search(ctx)
+}
+
+// 建立根節點
+func newRoot(game Game) *TreeNode {
+	return &TreeNode{
+		state:           game.Clone(),
+		unexploredMoves: expandableMoves(game),
 	}
-	return bestChild.selectNode()
 }
 
-// 擴展(Expansion)-優先探索尚未探索的位置，如果都探索了就跑selectNode
-func (t *TreeNode) expand() *TreeNode {
-	pos := 0
-	// 如果此節點已探索完成(len(t.unexploredPosz)==0)
-	if len(t.unexploredPosz) == 0 {
-		return t.selectNode()
-	} else {
-		// 隨機選擇一個未探索過的位置
-		posIndex := rand.Intn(len(t.unexploredPosz))
-		pos = t.unexploredPosz[posIndex]
-		// 移除選中的位置
-		t.unexploredPosz = append(t.unexploredPosz[:posIndex], t.unexploredPosz[posIndex+1:]...)
+// 可以擴展的動作，棋局已結束時沒有(有些遊戲在分出勝負後仍會返回空位)
+func expandableMoves(game Game) []int {
+	if terminal, _ := game.Result(); terminal {
+		return nil
 	}
+	return game.GetLegalMoves()
+}
 
-	// 設定新狀態為目前狀態並放置棋子
-	newState := *t.state
-	newState.Board[pos] = newState.CurrentPlayer()
-	newState.LastPlaced = pos
-	// 建立子節點
-	child := &TreeNode{
-		state:          &newState,
-		parent:         t,
-		unexploredPosz: newState.GetLegalPosz(),
+// 反覆迭代直到預算用完，每個goroutine使用自己的亂數來源，table為nil時不使用置換表
+func (root *TreeNode) run(b *budget, rng *rand.Rand, table *transpositionTable) {
+	policy := b.options.Rollout
+	if policy == nil {
+		policy = RandomRollout{}
+	}
+	selection := b.options.Selection
+	if selection == nil {
+		selection = NewUCT(math.Sqrt2)
+	}
+	rewards := b.options.Rewards
+	if rewards == (Rewards{}) {
+		rewards = DefaultRewards
+	}
+	for b.next() {
+		if proven, _ := root.provenResult(); proven {
+			break
+		}
+		root.addVisit()
+		path, move, prior, expand := root.selectPath(selection, rng)
+		node := path[len(path)-1]
+		if expand {
+			node = node.expand(b, table, move, prior)
+			path = append(path, node)
+		}
+		// 已證明的節點(包含棋局結束)直接使用證明的結果，不需要模擬
+		proven, winner := node.provenResult()
+		if !proven {
+			winner = node.simulate(policy, rng)
+		}
+		//fmt.Println("開始反向傳播:", node.state)
+		backpropagation(path, winner, rewards)
+		if proven {
+			propagateProof(path)
+		}
+		atomic.AddInt64(&b.completed, 1)
 	}
-	t.children = append(t.children, child)
+}
 
-	return child
+// 搜尋預算，可同時被多個goroutine使用
+type budget struct {
+	ctx        context.Context
+	options    Options
+	iterations int64 // 已請求的迭代次數(含被拒絕的請求)
+	completed  int64 // 已完成的迭代次數
+	nodes      int64
 }
 
-// 模擬(Rollout)-進行一次隨機模擬，並返回模擬結果中的贏家
-func (t *TreeNode) rollout() (int, *TreeNode) {
-	if t.state.GetGameState().IsTerminal {
-		return t.state.GetGameState().Winner, t
+// 取得下一次迭代的許可，預算用完時返回false(第一次迭代一定許可)
+func (b *budget) next() bool {
+	i := atomic.AddInt64(&b.iterations, 1)
+	if i == 1 {
+		return true
+	}
+	if b.options.MaxIterations > 0 && i > int64(b.options.MaxIterations) {
+		return false
 	}
+	if b.options.MaxNodes > 0 && atomic.LoadInt64(&b.nodes) >= int64(b.options.MaxNodes) {
+		return false
+	}
+	select {
+	case <-b.ctx.Done():
+		return false
+	default:
+		return true
+	}
+}
 
-	return t.expand().rollout()
+// 記錄新增的節點數
+func (b *budget) addNodes(n int64) {
+	atomic.AddInt64(&b.nodes, n)
 }
 
-// 反向傳播(Backpropagation)-每次模擬(Rollout)結束時，會根據模擬結果更新從根節點到該模擬結束的節點之間的所有節點資料
-func (t *TreeNode) backpropagation(winner int) {
+// 訪問次數+1
+func (t *TreeNode) addVisit() {
+	t.mu.Lock()
 	t.visits++
-	if winner == 0 {
-		t.wins += 0.1
-	} else if t.state.CurrentPlayer() != winner { // 贏家不等於目前玩家就勝利次數就+1 代表上個行動的玩家最後是贏棋的
-		t.wins++
-	}
-	if t.parent != nil {
-		t.parent.backpropagation(winner)
-	}
+	t.mu.Unlock()
 }
 
-// 找出目前節點中訪問次數最高的子節點並返回
-func (t *TreeNode) bestChild() *TreeNode {
-	var bestChild *TreeNode
-	maxVisits := math.Inf(-1)
+// 選擇(Selection)-從root往下走到分數最高的子節點，返回走過的路徑，root的訪問次數已經在開始迭代時加上
+// 未探索動作的分數不低於最佳子節點時停在該節點，返回要擴展的動作與其先驗機率(已從未探索動作中移除)
+// 走到已結束或已證明的棋局時返回的expand為false
+func (root *TreeNode) selectPath(policy SelectionPolicy, rng *rand.Rand) (path []*TreeNode, move int, prior float64, expand bool) {
+	t, tPrior := root, 0.0
+	for {
+		path = append(path, t)
+		t.mu.Lock()
+		if t.proven {
+			t.mu.Unlock()
+			return path, -1, 0, false
+		}
+		if t.priors == nil && len(t.unexploredMoves) > 0 {
+			t.initPriors(policy)
+		}
+		parent := NodeStats{Visits: t.visits, Wins: t.wins, Squares: t.squares, Prior: tPrior}
 
-	for _, child := range t.children {
-		if child.visits > maxVisits {
-			maxVisits = child.visits
-			bestChild = child
+		mover := t.state.CurrentPlayer()
+		var best *edge
+		bestScore := math.Inf(-1)
+		for i := range t.children {
+			e := &t.children[i]
+			stats := e.node.nodeStats()
+			stats.Prior = e.prior
+			score := provenScore(e.node, mover, policy.Score(parent, stats))
+			if best == nil || score > bestScore {
+				bestScore = score
+				best = e
+			}
+		}
+
+		// 未探索的動作以訪問次數0計分，同分時隨機選擇
+		var candidates []int
+		bestMoveScore := math.Inf(-1)
+		for i := range t.unexploredMoves {
+			score := policy.Score(parent, NodeStats{Prior: t.priors[i]})
+			if candidates == nil || score > bestMoveScore {
+				bestMoveScore = score
+				candidates = candidates[:0]
+			}
+			if score == bestMoveScore {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) > 0 && (best == nil || bestMoveScore >= bestScore) {
+			i := candidates[rng.Intn(len(candidates))]
+			move, prior = t.unexploredMoves[i], t.priors[i]
+			t.unexploredMoves = append(t.unexploredMoves[:i], t.unexploredMoves[i+1:]...)
+			t.priors = append(t.priors[:i], t.priors[i+1:]...)
+			t.expanding++
+			t.mu.Unlock()
+			return path, move, prior, true
+		}
+		// 沒有子節點也沒有未探索動作，代表棋局已結束
+		if best == nil {
+			t.mu.Unlock()
+			return path, -1, 0, false
 		}
+		child, childPrior := best.node, best.prior
+		t.mu.Unlock()
+
+		// 先加上訪問次數，其他goroutine在反向傳播前看到的勝率會較低(虛擬損失)
+		child.addVisit()
+		t, tPrior = child, childPrior
+	}
+}
+
+// 擴展(Expansion)-為選擇階段挑出的動作建立子節點
+// 使用置換表且相同局面的節點已經存在時直接連到該節點，不另外建立
+func (t *TreeNode) expand(b *budget, table *transpositionTable, move int, prior float64) *TreeNode {
+	// 複製目前狀態並執行動作
+	// 每個節點都保存自己的棋況(樹重用、置換表與平行搜尋都直接讀取節點的棋況)，所以這裡不使用Play/Undo
+	newState := t.state.Clone()
+	player := newState.CurrentPlayer()
+	newState.ApplyMove(move)
+	create := func() *TreeNode {
+		child := &TreeNode{
+			state:           newState,
+			player:          player,
+			visits:          1,
+			unexploredMoves: expandableMoves(newState),
+		}
+		// 啟用MCTS-Solver時，棋局結束的節點直接證明
+		if terminal, winner := newState.Result(); terminal && b.options.Solver {
+			child.proven, child.provenWinner = true, winner
+		}
+		return child
+	}
+
+	var child *TreeNode
+	if table == nil {
+		child = create()
+		b.addNodes(1)
+	} else if node, created := table.getOrAdd(newState, create); created {
+		child = node
+		b.addNodes(1)
+	} else {
+		child = node
+		child.addVisit()
+	}
+	t.mu.Lock()
+	t.children = append(t.children, edge{move: move, prior: prior, node: child})
+	t.expanding--
+	t.mu.Unlock()
+
+	return child
+}
+
+// 反向傳播(Backpropagation)-每次模擬(Rollout)結束時，會根據模擬結果更新這次迭代從根節點走到擴展出的節點的路徑上所有節點資料
+// 獎勵以走到各節點的玩家(上個行動的玩家)角度計算
+func backpropagation(path []*TreeNode, winner int, rewards Rewards) {
+	for _, t := range path {
+		reward := rewards.Of(t.player, winner)
+		t.mu.Lock()
+		t.wins += reward
+		t.squares += reward * reward
+		t.mu.Unlock()
 	}
-	return bestChild
 }
@@ -0,0 +1,32 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// 根平行化：每個goroutine各自有一棵樹，共用預算，最後由mergeMoveStats把各樹根節點子節點的統計依動作加總
+func rootParallelSearch(roots []*TreeNode, tables []*transpositionTable, b *budget, rngs []*rand.Rand) {
+	var wg sync.WaitGroup
+	for i, root := range roots {
+		wg.Add(1)
+		go func(root *TreeNode, table *transpositionTable, rng *rand.Rand) {
+			defer wg.Done()
+			root.run(b, rng, table)
+		}(root, tables[i], rngs[i])
+	}
+	wg.Wait()
+}
+
+// 樹平行化：所有goroutine共用同一棵樹，節點統計以節點的mutex保護，並以虛擬損失分散各goroutine走的路徑
+func treeParallelSearch(root *TreeNode, table *transpositionTable, b *budget, rngs []*rand.Rand) {
+	var wg sync.WaitGroup
+	for _, rng := range rngs {
+		wg.Add(1)
+		go func(rng *rand.Rand) {
+			defer wg.Done()
+			root.run(b, rng, table)
+		}(rng)
+	}
+	wg.Wait()
+}
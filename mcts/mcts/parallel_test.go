@@ -0,0 +1,38 @@
+package mcts_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+)
+
+// 每次搜尋的迭代次數，比較不同平行方式時ns/op越小代表加速越多
+const benchmarkIterations = 20000
+
+func benchmarkSearch(b *testing.B, options mcts.Options) {
+	options.MaxIterations = benchmarkIterations
+	options.Seed = 1
+	game := tictactoe.New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := mcts.Search(context.Background(), game, options); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchSerial(b *testing.B) {
+	benchmarkSearch(b, mcts.Options{})
+}
+
+// 以GOMAXPROCS個goroutine搜尋，可用 -cpu 1,2,4,8 比較不同goroutine數
+func BenchmarkSearchRootParallel(b *testing.B) {
+	benchmarkSearch(b, mcts.Options{Workers: runtime.GOMAXPROCS(0), Parallel: mcts.RootParallel})
+}
+
+func BenchmarkSearchTreeParallel(b *testing.B) {
+	benchmarkSearch(b, mcts.Options{Workers: runtime.GOMAXPROCS(0), Parallel: mcts.TreeParallel})
+}
@@ -0,0 +1,150 @@
+package mcts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 一次搜尋的結果與統計，供介面顯示信心程度或分析AI的選擇
+type SearchResult struct {
+	Move               int           // 依FinalMove選出的動作
+	Moves              []MoveStats   // 根節點每個動作的統計，依訪問次數由多到少排序
+	PrincipalVariation []int         // 主要變化：從Move開始每層都走訪問次數最多的子節點
+	Iterations         int           // 這次搜尋完成的迭代次數
+	Nodes              int           // 搜尋後所有樹的節點數(含之前保留的節點)
+	Depth              int           // 樹的最大深度(根節點為0)
+	Elapsed            time.Duration // 這次搜尋花費的時間
+	Solved             bool          // MCTS-Solver已證明根節點的結果
+	Winner             int           // Solved時雙方完美下法的贏家(0代表和局)
+}
+
+// 搜尋並返回完整的搜尋結果，每次呼叫都會建立新的搜尋樹
+func Analyze(ctx context.Context, game Game, options Options) (*SearchResult, error) {
+	return NewSearcher(game, options).Analyze(ctx)
+}
+
+// 取得move的統計，沒有搜尋過該動作時ok為false
+func (r *SearchResult) Stats(move int) (stats MoveStats, ok bool) {
+	for _, s := range r.Moves {
+		if s.Move == move {
+			return s, true
+		}
+	}
+	return MoveStats{}, false
+}
+
+// 以文字輸出搜尋結果，moveString為nil時直接輸出動作編號
+func (r *SearchResult) Format(moveString func(int) string) string {
+	if moveString == nil {
+		moveString = func(move int) string { return fmt.Sprint(move) }
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "最佳動作 %s  迭代%d次 節點%d個 深度%d 耗時%v\n",
+		moveString(r.Move), r.Iterations, r.Nodes, r.Depth, r.Elapsed.Round(time.Microsecond))
+	total := r.totalVisits()
+	if r.Solved {
+		fmt.Fprintf(&sb, "  已證明: %s\n", provenString(r.Winner))
+	}
+	for _, s := range r.Moves {
+		share := 0.0
+		if total > 0 {
+			share = float64(s.Visits) / float64(total)
+		}
+		fmt.Fprintf(&sb, "  %-6s 訪問%6d次(%5.1f%%) 平均價值%.3f", moveString(s.Move), s.Visits, share*100, s.Value)
+		if s.Proven {
+			fmt.Fprintf(&sb, " 已證明: %s", provenString(s.Winner))
+		}
+		sb.WriteString("\n")
+	}
+	pv := make([]string, len(r.PrincipalVariation))
+	for i, move := range r.PrincipalVariation {
+		pv[i] = moveString(move)
+	}
+	fmt.Fprintf(&sb, "  主要變化: %s", strings.Join(pv, " "))
+	return sb.String()
+}
+
+func (r *SearchResult) String() string {
+	return r.Format(nil)
+}
+
+// 已證明結果的文字
+func provenString(winner int) string {
+	if winner == 0 {
+		return "和局"
+	}
+	return fmt.Sprintf("玩家%d獲勝", winner)
+}
+
+// 根節點所有動作的訪問次數總和
+func (r *SearchResult) totalVisits() int {
+	total := 0
+	for _, s := range r.Moves {
+		total += s.Visits
+	}
+	return total
+}
+
+// 主要變化：第一步為move，之後在該動作訪問次數最多的那棵樹中每層走訪問次數最多的子節點
+func principalVariation(roots []*TreeNode, move int) []int {
+	var node *TreeNode
+	for _, root := range roots {
+		if child := root.child(move); child != nil && (node == nil || child.visits > node.visits) {
+			node = child
+		}
+	}
+	if node == nil {
+		return nil
+	}
+	pv := []int{move}
+	for {
+		e := node.mostVisitedChild()
+		if e == nil {
+			return pv
+		}
+		pv = append(pv, e.move)
+		node = e.node
+	}
+}
+
+// 取得move對應的子節點，沒有時返回nil
+func (t *TreeNode) child(move int) *TreeNode {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.children {
+		if e.move == move {
+			return e.node
+		}
+	}
+	return nil
+}
+
+// 訪問次數最多的子節點，沒有子節點時返回nil
+func (t *TreeNode) mostVisitedChild() *edge {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var best *edge
+	for i := range t.children {
+		if e := &t.children[i]; best == nil || e.node.visits > best.node.visits {
+			best = e
+		}
+	}
+	return best
+}
+
+// 子樹的最大深度(t為0)，depths記錄已計算過的節點，避免置換表共用的節點重複計算
+func (t *TreeNode) depth(depths map[*TreeNode]int) int {
+	if d, ok := depths[t]; ok {
+		return d
+	}
+	d := 0
+	for _, e := range t.children {
+		if cd := e.node.depth(depths) + 1; cd > d {
+			d = cd
+		}
+	}
+	depths[t] = d
+	return d
+}
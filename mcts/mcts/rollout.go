@@ -0,0 +1,66 @@
+package mcts
+
+import "math/rand"
+
+// 模擬(Rollout)階段的下棋策略，從moves(目前合法的動作)中選一個
+// 模擬只在遊戲的複本上進行，不會建立樹節點
+type RolloutPolicy interface {
+	ChooseMove(game Game, moves []int, rng *rand.Rand) int
+}
+
+// 均勻隨機的模擬策略
+type RandomRollout struct{}
+
+func (RandomRollout) ChooseMove(game Game, moves []int, rng *rand.Rand) int {
+	return moves[rng.Intn(len(moves))]
+}
+
+// 啟發式模擬策略：能直接獲勝就下(win-first)，否則避開會讓對手下一步直接獲勝的動作(block-first)，其餘隨機
+// 每一步需要試下所有動作與對手的所有回應，分支多的遊戲會明顯變慢
+type HeuristicRollout struct{}
+
+func (HeuristicRollout) ChooseMove(game Game, moves []int, rng *rand.Rand) int {
+	player := game.CurrentPlayer()
+	var safeMoves []int
+	for _, move := range moves {
+		next := game.Clone()
+		next.ApplyMove(move)
+		if terminal, winner := next.Result(); terminal {
+			if winner == player {
+				return move
+			}
+			safeMoves = append(safeMoves, move)
+			continue
+		}
+		if !opponentCanWin(next, player) {
+			safeMoves = append(safeMoves, move)
+		}
+	}
+	if len(safeMoves) == 0 {
+		safeMoves = moves
+	}
+	return safeMoves[rng.Intn(len(safeMoves))]
+}
+
+// 判斷輪到對手時，對手是否有一步就能獲勝的動作
+func opponentCanWin(game Game, player int) bool {
+	for _, reply := range game.GetLegalMoves() {
+		next := game.Clone()
+		next.ApplyMove(reply)
+		if terminal, winner := next.Result(); terminal && winner != player && winner != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// 從節點的狀態開始依策略模擬到棋局結束，返回贏家
+func (t *TreeNode) simulate(policy RolloutPolicy, rng *rand.Rand) int {
+	game := t.state.Clone()
+	for {
+		if terminal, winner := game.Result(); terminal {
+			return winner
+		}
+		game.ApplyMove(policy.ChooseMove(game, game.GetLegalMoves(), rng))
+	}
+}
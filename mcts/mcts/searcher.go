@@ -0,0 +1,189 @@
+package mcts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+var ErrIllegalMove = errors.New("mcts: 不合法的動作")
+
+// 可以跨回合保留搜尋樹的搜尋器
+// 每次實際下棋(不論是自己或對手)後呼叫Advance，根節點會移到對應的子節點並保留其統計，其餘分支則丟棄
+type Searcher struct {
+	options Options
+	roots   []*TreeNode           // 根平行化時每個goroutine各有一棵樹，其餘情況只有一棵
+	tables  []*transpositionTable // 每棵樹的置換表，沒有啟用置換表時為nil
+	nodes   int64                 // 目前所有樹的節點數
+	rng     *rand.Rand            // 產生每次搜尋各goroutine亂數來源的亂數來源
+}
+
+// 建立搜尋器，options的預算用於每一次Search
+func NewSearcher(game Game, options Options) *Searcher {
+	s := &Searcher{options: options, rng: options.Rand}
+	if s.rng == nil {
+		seed := options.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		s.rng = rand.New(rand.NewSource(seed))
+	}
+	trees := 1
+	if options.Workers > 1 && options.Parallel == RootParallel {
+		trees = options.Workers
+	}
+	_, hashable := game.(Hasher)
+	for i := 0; i < trees; i++ {
+		s.roots = append(s.roots, newRoot(game))
+		if options.Transpositions && hashable {
+			s.tables = append(s.tables, newTranspositionTable(s.roots[i]))
+		}
+	}
+	s.nodes = int64(trees)
+	return s
+}
+
+// 第i棵樹的置換表，沒有啟用置換表時為nil
+func (s *Searcher) table(i int) *transpositionTable {
+	if s.tables == nil {
+		return nil
+	}
+	return s.tables[i]
+}
+
+// 取得目前根節點的遊戲狀態(複本)
+func (s *Searcher) Game() Game {
+	return s.roots[0].state.Clone()
+}
+
+// 目前保留的節點數
+func (s *Searcher) Nodes() int {
+	return int(s.nodes)
+}
+
+// 從目前的根節點繼續搜尋，沿用之前累積的統計，返回目前找到的最佳動作
+func (s *Searcher) Search(ctx context.Context) (int, error) {
+	result, err := s.Analyze(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return result.Move, nil
+}
+
+// 與Search相同，但返回完整的搜尋結果
+func (s *Searcher) Analyze(ctx context.Context) (*SearchResult, error) {
+	start := time.Now()
+	options := s.options
+	// 有些遊戲在分出勝負後仍會返回空位，先用Result判斷棋局是否結束
+	if terminal, _ := s.roots[0].state.Result(); terminal {
+		return nil, ErrGameOver
+	}
+	if len(s.roots[0].state.GetLegalMoves()) == 0 {
+		return nil, ErrNoLegalMove
+	}
+	if options.Transpositions && s.tables == nil {
+		return nil, ErrNoHash
+	}
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && ctx.Done() == nil &&
+		options.MaxIterations <= 0 && options.MaxNodes <= 0 && options.Duration <= 0 {
+		return nil, ErrNoBudget
+	}
+	if options.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Duration)
+		defer cancel()
+	}
+
+	b := &budget{ctx: ctx, options: options, nodes: s.nodes}
+	switch {
+	case options.Workers <= 1:
+		s.roots[0].run(b, s.newRand(), s.table(0))
+	case options.Parallel == TreeParallel:
+		treeParallelSearch(s.roots[0], s.table(0), b, s.newRands(options.Workers))
+	default:
+		tables := make([]*transpositionTable, len(s.roots))
+		for i := range tables {
+			tables[i] = s.table(i)
+		}
+		rootParallelSearch(s.roots, tables, b, s.newRands(len(s.roots)))
+	}
+	s.nodes = b.nodes
+
+	result := &SearchResult{
+		Moves:      s.MoveStats(),
+		Iterations: int(b.completed),
+		Nodes:      int(s.nodes),
+	}
+	result.Move = chooseFinalMove(result.Moves, options.FinalMove, s.roots[0].state.CurrentPlayer())
+	result.PrincipalVariation = principalVariation(s.roots, result.Move)
+	for _, root := range s.roots {
+		if d := root.depth(make(map[*TreeNode]int)); d > result.Depth {
+			result.Depth = d
+		}
+		if proven, winner := root.provenResult(); proven {
+			result.Solved, result.Winner = true, winner
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// 根節點每個動作目前的搜尋統計，依訪問次數由多到少排序
+func (s *Searcher) MoveStats() []MoveStats {
+	return mergeMoveStats(s.roots)
+}
+
+// 建立一個亂數來源
+func (s *Searcher) newRand() *rand.Rand {
+	return s.newRands(1)[0]
+}
+
+// 為每個goroutine建立各自的亂數來源(*rand.Rand不能在多個goroutine間共用)，種子由搜尋器的亂數來源產生
+func (s *Searcher) newRands(n int) []*rand.Rand {
+	rngs := make([]*rand.Rand, n)
+	for i := range rngs {
+		rngs[i] = rand.New(rand.NewSource(s.rng.Int63()))
+	}
+	return rngs
+}
+
+// 實際執行了move後，把根節點移到對應的子節點，其餘分支丟棄
+func (s *Searcher) Advance(move int) error {
+	if !contains(s.roots[0].state.GetLegalMoves(), move) {
+		return fmt.Errorf("%w: %d", ErrIllegalMove, move)
+	}
+	s.nodes = 0
+	for i, root := range s.roots {
+		s.roots[i] = root.advance(move)
+		s.nodes += int64(len(s.roots[i].reachable()))
+		// 置換表只保留新根節點可以到達的節點，其餘節點才能被回收
+		if s.tables != nil {
+			s.tables[i] = newTranspositionTable(s.roots[i])
+		}
+	}
+	return nil
+}
+
+// 取得move對應的子節點作為新的根節點，子節點還沒建立時建立新的根節點
+func (t *TreeNode) advance(move int) *TreeNode {
+	if child := t.child(move); child != nil {
+		return child
+	}
+	state := t.state.Clone()
+	player := state.CurrentPlayer()
+	state.ApplyMove(move)
+	root := newRoot(state)
+	root.player = player
+	return root
+}
+
+func contains(moves []int, move int) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package mcts_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+)
+
+func TestSearchFinishedGame(t *testing.T) {
+	game := tictactoe.New()
+	for _, pos := range []int{0, 3, 1, 4, 2} { // 玩家1連成第一列，棋盤上仍有空位
+		game.Play(pos)
+	}
+	move, err := mcts.Search(context.Background(), game, mcts.Options{MaxIterations: 100})
+	if !errors.Is(err, mcts.ErrGameOver) {
+		t.Errorf("Search(已結束的棋局) = (%d, %v)，預期錯誤 %v", move, err, mcts.ErrGameOver)
+	}
+	if _, err := mcts.NewSearcher(game, mcts.Options{MaxIterations: 100}).Analyze(context.Background()); !errors.Is(err, mcts.ErrGameOver) {
+		t.Errorf("Analyze(已結束的棋局)的錯誤為 %v，預期為 %v", err, mcts.ErrGameOver)
+	}
+}
+
+// 單執行緒搜尋時相同的種子要得到完全相同的統計與主要變化
+func TestSameSeedSameResult(t *testing.T) {
+	analyze := func() *mcts.SearchResult {
+		result, err := mcts.Analyze(context.Background(), tictactoe.New(), mcts.Options{MaxIterations: 3000, Seed: 42})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	first, second := analyze(), analyze()
+	if !reflect.DeepEqual(first.Moves, second.Moves) {
+		t.Errorf("相同種子的動作統計不同:\n%v\n%v", first.Moves, second.Moves)
+	}
+	if !reflect.DeepEqual(first.PrincipalVariation, second.PrincipalVariation) || first.Move != second.Move {
+		t.Errorf("相同種子的主要變化不同: %v / %v", first.PrincipalVariation, second.PrincipalVariation)
+	}
+}
@@ -0,0 +1,120 @@
+package mcts
+
+import "math"
+
+// 選擇(Selection)階段計算分數所需的節點資料
+// Wins與Squares是以執行該節點動作的玩家角度累積的獎勵與獎勵平方
+type NodeStats struct {
+	Visits  float64 // 訪問次數(含進行中的模擬)，0代表尚未擴展的動作
+	Wins    float64 // 累積獎勵
+	Squares float64 // 累積獎勵平方
+	Prior   float64 // 先驗機率，沒有先驗時為平均分配
+}
+
+// 平均獎勵，沒有訪問過時為0
+func (s NodeStats) Mean() float64 {
+	if s.Visits == 0 {
+		return 0
+	}
+	return s.Wins / s.Visits
+}
+
+// 選擇策略，選擇時會往分數最高的子節點走
+// 尚未擴展的動作也會以Visits為0計分，分數不低於最佳子節點時優先擴展
+type SelectionPolicy interface {
+	Score(parent, child NodeStats) float64
+}
+
+// 提供先驗機率的選擇策略(例如PUCT)，節點第一次被選擇時計算一次
+type PriorPolicy interface {
+	Priors(game Game, moves []int) []float64
+}
+
+// UCT = (w / n) + C * sqrt(ln(N) / n)
+// 前半段的(w / n)代表利用 後半段的C * sqrt(ln(N) / n)代表探索  C越大會使AI更注重探索，C越小會使AI注重利用
+type UCT struct {
+	C   float64
+	FPU float64 // 未訪問動作的分數(first-play urgency)，+Inf代表先擴展完所有動作
+}
+
+// 建立未訪問動作分數為+Inf的UCT(與傳統UCT相同)，C通常是sqrt(2)
+func NewUCT(c float64) UCT {
+	return UCT{C: c, FPU: math.Inf(1)}
+}
+
+func (p UCT) Score(parent, child NodeStats) float64 {
+	if child.Visits == 0 {
+		return p.FPU
+	}
+	return child.Mean() + p.C*math.Sqrt(math.Log(parent.Visits)/child.Visits)
+}
+
+// UCB1-Tuned，以獎勵的變異數調整探索項，變異數小的子節點探索得較少
+// 探索項 = sqrt(ln(N) / n * min(1/4, V))，V = 平方平均 - 平均平方 + sqrt(2ln(N) / n)
+type UCB1Tuned struct {
+	FPU float64 // 未訪問動作的分數，+Inf代表先擴展完所有動作
+}
+
+func NewUCB1Tuned() UCB1Tuned {
+	return UCB1Tuned{FPU: math.Inf(1)}
+}
+
+func (p UCB1Tuned) Score(parent, child NodeStats) float64 {
+	if child.Visits == 0 {
+		return p.FPU
+	}
+	logN := math.Log(parent.Visits)
+	mean := child.Mean()
+	variance := child.Squares/child.Visits - mean*mean + math.Sqrt(2*logN/child.Visits)
+	return mean + math.Sqrt(logN/child.Visits*math.Min(0.25, variance))
+}
+
+// PUCT(AlphaZero使用的選擇公式)：Q + C * P * sqrt(N) / (1 + n)
+// 未訪問動作的Q為FPU，先驗機率高的動作會先被擴展
+type PUCT struct {
+	C     float64
+	FPU   float64                                // 未訪問動作的Q值，例如0(悲觀)或0.5
+	Prior func(game Game, moves []int) []float64 // 動作的先驗機率，nil時為平均分配
+}
+
+func (p PUCT) Score(parent, child NodeStats) float64 {
+	q := p.FPU
+	if child.Visits > 0 {
+		q = child.Mean()
+	}
+	return q + p.C*child.Prior*math.Sqrt(parent.Visits)/(1+child.Visits)
+}
+
+func (p PUCT) Priors(game Game, moves []int) []float64 {
+	if p.Prior == nil {
+		return uniformPriors(len(moves))
+	}
+	return p.Prior(game, moves)
+}
+
+// 平均分配的先驗機率
+func uniformPriors(n int) []float64 {
+	priors := make([]float64, n)
+	for i := range priors {
+		priors[i] = 1 / float64(n)
+	}
+	return priors
+}
+
+// 計算節點未探索動作的先驗機率(呼叫時需持有t.mu)，先驗數量與動作不符時改為平均分配
+func (t *TreeNode) initPriors(policy SelectionPolicy) {
+	if p, ok := policy.(PriorPolicy); ok {
+		if priors := p.Priors(t.state, t.unexploredMoves); len(priors) == len(t.unexploredMoves) {
+			t.priors = append([]float64(nil), priors...)
+			return
+		}
+	}
+	t.priors = uniformPriors(len(t.unexploredMoves))
+}
+
+// 取得節點資料(先驗機率屬於父節點的動作，由呼叫者填入)
+func (t *TreeNode) nodeStats() NodeStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return NodeStats{Visits: t.visits, Wins: t.wins, Squares: t.squares}
+}
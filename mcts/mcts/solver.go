@@ -0,0 +1,78 @@
+package mcts
+
+import "math"
+
+// MCTS-Solver：把棋局結束的結果當作已證明的值往上傳遞
+// 節點的行動方只要有一個子節點證明為自己獲勝，該節點就證明為行動方獲勝；
+// 所有子節點都已證明時，有和局就證明為和局，否則證明為對手獲勝
+// 已證明的節點不再模擬，選擇時避開證明為對手獲勝的子節點，根節點證明後提前停止搜尋
+
+// 節點是否已證明與證明的贏家(0代表和局)
+func (t *TreeNode) provenResult() (proven bool, winner int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.proven, t.provenWinner
+}
+
+// 以子節點的證明嘗試證明t，返回t是否已證明
+func (t *TreeNode) tryProve() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.proven {
+		return true
+	}
+	mover := t.state.CurrentPlayer()
+	// 還有未擴展或正在擴展的動作時只能證明行動方獲勝
+	allProven := len(t.unexploredMoves) == 0 && t.expanding == 0 && len(t.children) > 0
+	draw, opponent := false, 0
+	for _, e := range t.children {
+		proven, winner := e.node.provenResult()
+		switch {
+		case !proven:
+			allProven = false
+		case winner == mover:
+			t.proven, t.provenWinner = true, mover
+			return true
+		case winner == 0:
+			draw = true
+		default:
+			opponent = winner
+		}
+	}
+	if !allProven {
+		return false
+	}
+	t.proven = true
+	if draw {
+		t.provenWinner = 0
+	} else {
+		t.provenWinner = opponent
+	}
+	return true
+}
+
+// 路徑最後的節點已證明時，沿著路徑往上嘗試證明父節點，直到無法證明為止
+// 啟用置換表時不在路徑上的其他父節點，會在之後走到這個已證明的節點時再證明
+func propagateProof(path []*TreeNode) {
+	for i := len(path) - 1; i > 0; i-- {
+		if proven, _ := path[i].provenResult(); !proven || !path[i-1].tryProve() {
+			return
+		}
+	}
+}
+
+// 選擇時已證明子節點的分數：必勝的子節點一定選，對行動方必敗的子節點永遠不選
+// 已證明和局的子節點再搜尋也不會改變結果，只在沒有未證明的子節點時才選
+func provenScore(child *TreeNode, mover int, score float64) float64 {
+	proven, winner := child.provenResult()
+	switch {
+	case !proven:
+		return score
+	case winner == 0:
+		return -math.MaxFloat64
+	case winner == mover:
+		return math.Inf(1)
+	default:
+		return math.Inf(-1)
+	}
+}
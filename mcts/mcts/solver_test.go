@@ -0,0 +1,87 @@
+package mcts_test
+
+import (
+	"context"
+	"testing"
+
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+)
+
+// 依序下moves得到的棋況
+func position(moves ...int) *tictactoe.GameState {
+	game := tictactoe.New()
+	for _, move := range moves {
+		game.Play(move)
+	}
+	return game
+}
+
+// 玩家1(0,1)下2就贏：根節點馬上被證明，搜尋應該提前結束並選擇致勝的動作
+func TestSolverWinInOne(t *testing.T) {
+	const maxIterations = 10000
+	game := position(0, 3, 1, 4)
+	result, err := mcts.Analyze(context.Background(), game, mcts.Options{MaxIterations: maxIterations, Solver: true, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Solved || result.Winner != tictactoe.Player1 || result.Move != 2 {
+		t.Errorf("結果為 Solved=%v Winner=%d Move=%d，預期為 Solved=true Winner=1 Move=2", result.Solved, result.Winner, result.Move)
+	}
+	if result.Iterations >= maxIterations {
+		t.Errorf("根節點證明後應該提前停止，實際迭代%d次", result.Iterations)
+	}
+}
+
+// 玩家2(1,4)威脅下7連線，玩家1只有擋7不會輸；不論預算多少都不能選到已證明會輸的動作
+func TestSolverAvoidsProvenLosses(t *testing.T) {
+	game := position(0, 4, 8, 1)
+	for _, iterations := range []int{20, 50, 100, 300, 1000} {
+		for seed := int64(1); seed <= 20; seed++ {
+			result, err := mcts.Analyze(context.Background(), game, mcts.Options{MaxIterations: iterations, Solver: true, Seed: seed})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats, ok := result.Stats(result.Move)
+			if !ok {
+				t.Fatalf("迭代%d次(種子%d)選擇的動作%d沒有統計", iterations, seed, result.Move)
+			}
+			if stats.Proven && stats.Winner == tictactoe.Player2 {
+				t.Errorf("迭代%d次(種子%d)選擇了已證明會輸的動作%d", iterations, seed, result.Move)
+			}
+			if iterations >= 1000 && result.Move != 7 {
+				t.Errorf("迭代%d次(種子%d)選擇%d，預期擋在7", iterations, seed, result.Move)
+			}
+		}
+	}
+}
+
+// 預算足夠時可以從空棋盤證明井字棋是和局，啟用置換表時需要的迭代次數較少
+func TestSolverProvesEmptyBoardDraw(t *testing.T) {
+	if testing.Short() {
+		t.Skip("需要數萬次迭代")
+	}
+	const maxIterations = 200000
+	var iterations [2]int
+	for i, transpositions := range []bool{false, true} {
+		result, err := mcts.Analyze(context.Background(), tictactoe.New(), mcts.Options{
+			MaxIterations:  maxIterations,
+			Solver:         true,
+			Transpositions: transpositions,
+			Seed:           1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Solved || result.Winner != tictactoe.None {
+			t.Fatalf("置換表=%v: Solved=%v Winner=%d，預期證明為和局", transpositions, result.Solved, result.Winner)
+		}
+		if result.Iterations >= maxIterations {
+			t.Errorf("置換表=%v: 證明後應該提前停止，實際迭代%d次", transpositions, result.Iterations)
+		}
+		iterations[i] = result.Iterations
+	}
+	if iterations[1] >= iterations[0] {
+		t.Errorf("啟用置換表後證明需要%d次迭代，沒有少於不啟用時的%d次", iterations[1], iterations[0])
+	}
+}
@@ -0,0 +1,47 @@
+package mcts
+
+import "sync"
+
+// 置換表：以局面的雜湊值找到節點，讓不同走法到達的相同局面共用同一個節點(搜尋樹變成DAG)
+// 假設遊戲不會重複出現相同局面(沒有循環)，井字棋與黑白棋的棋子數只會增加所以成立
+type transpositionTable struct {
+	mu    sync.Mutex
+	nodes map[uint64]*TreeNode
+}
+
+// 建立只包含root可以到達的節點的置換表
+func newTranspositionTable(root *TreeNode) *transpositionTable {
+	table := &transpositionTable{nodes: make(map[uint64]*TreeNode)}
+	for _, node := range root.reachable() {
+		table.nodes[node.state.(Hasher).Hash()] = node
+	}
+	return table
+}
+
+// 取得局面對應的節點，沒有時以create建立並加入置換表
+func (table *transpositionTable) getOrAdd(state Game, create func() *TreeNode) (node *TreeNode, created bool) {
+	hash := state.(Hasher).Hash()
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	if node, ok := table.nodes[hash]; ok {
+		return node, false
+	}
+	node = create()
+	table.nodes[hash] = node
+	return node, true
+}
+
+// t可以到達的所有節點(含t，每個節點只出現一次)
+func (t *TreeNode) reachable() []*TreeNode {
+	visited := map[*TreeNode]bool{t: true}
+	nodes := []*TreeNode{t}
+	for i := 0; i < len(nodes); i++ {
+		for _, e := range nodes[i].children {
+			if !visited[e.node] {
+				visited[e.node] = true
+				nodes = append(nodes, e.node)
+			}
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,130 @@
+package mcts
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// 測試用的遊戲：雙方輪流在5格中放棋子，放滿時和局
+// 不同的下棋順序會到達相同的局面，所以可以用來測試置換表
+type fillGame struct {
+	board [5]int
+	moves int
+}
+
+func (g *fillGame) GetLegalMoves() []int {
+	var moves []int
+	for pos, player := range g.board {
+		if player == 0 {
+			moves = append(moves, pos)
+		}
+	}
+	return moves
+}
+
+func (g *fillGame) ApplyMove(pos int) {
+	g.board[pos] = g.CurrentPlayer()
+	g.moves++
+}
+
+func (g *fillGame) Clone() Game {
+	clone := *g
+	return &clone
+}
+
+func (g *fillGame) CurrentPlayer() int {
+	return 1 + g.moves%2
+}
+
+func (g *fillGame) Result() (bool, int) {
+	return g.moves == len(g.board), 0
+}
+
+func (g *fillGame) Hash() uint64 {
+	var hash uint64
+	for _, player := range g.board {
+		hash = hash*3 + uint64(player)
+	}
+	return hash
+}
+
+// 沒有實作Hasher的fillGame
+type unhashedGame struct {
+	game *fillGame
+}
+
+func (g unhashedGame) GetLegalMoves() []int { return g.game.GetLegalMoves() }
+func (g unhashedGame) ApplyMove(pos int)    { g.game.ApplyMove(pos) }
+func (g unhashedGame) Clone() Game          { return unhashedGame{g.game.Clone().(*fillGame)} }
+func (g unhashedGame) CurrentPlayer() int   { return g.game.CurrentPlayer() }
+func (g unhashedGame) Result() (bool, int)  { return g.game.Result() }
+
+// 搜尋到整棵遊戲樹都展開為止
+func searchAll(t *testing.T, options Options) *Searcher {
+	t.Helper()
+	options.MaxIterations = 5000
+	options.Seed = 1
+	searcher := NewSearcher(&fillGame{}, options)
+	if _, err := searcher.Analyze(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return searcher
+}
+
+func TestTranspositionsShareNodes(t *testing.T) {
+	tree := searchAll(t, Options{})
+	dag := searchAll(t, Options{Transpositions: true})
+	if dag.Nodes() >= tree.Nodes() {
+		t.Errorf("啟用置換表的節點數%d沒有少於不啟用時的%d", dag.Nodes(), tree.Nodes())
+	}
+
+	// 0,1,2與2,1,0的局面相同(玩家1在0與2，玩家2在1)
+	root := dag.Root()
+	a := root.child(0).child(1).child(2)
+	b := root.child(2).child(1).child(0)
+	if a == nil || a != b {
+		t.Errorf("不同順序到達的相同局面沒有共用節點: %p / %p", a, b)
+	}
+	if c := tree.Root().child(0).child(1).child(2); c == tree.Root().child(2).child(1).child(0) {
+		t.Error("沒有啟用置換表時不應該共用節點")
+	}
+	if len(dag.tables[0].nodes) != dag.Nodes() {
+		t.Errorf("置換表有%d個節點，搜尋器記錄%d個", len(dag.tables[0].nodes), dag.Nodes())
+	}
+}
+
+func TestTranspositionsRequireHasher(t *testing.T) {
+	searcher := NewSearcher(unhashedGame{&fillGame{}}, Options{MaxIterations: 100, Transpositions: true})
+	if _, err := searcher.Analyze(context.Background()); !errors.Is(err, ErrNoHash) {
+		t.Errorf("沒有實作Hasher的遊戲啟用置換表時錯誤為 %v，預期為 %v", err, ErrNoHash)
+	}
+}
+
+func TestAdvanceRebuildsTranspositionTable(t *testing.T) {
+	searcher := searchAll(t, Options{Transpositions: true})
+	before := len(searcher.tables[0].nodes)
+	if err := searcher.Advance(0); err != nil {
+		t.Fatal(err)
+	}
+
+	reachable := make(map[*TreeNode]bool)
+	for _, node := range searcher.Root().reachable() {
+		reachable[node] = true
+	}
+	table := searcher.tables[0].nodes
+	if len(table) != len(reachable) || searcher.Nodes() != len(reachable) {
+		t.Fatalf("置換表有%d個節點、搜尋器記錄%d個，預期都是可到達的%d個", len(table), searcher.Nodes(), len(reachable))
+	}
+	if len(table) >= before {
+		t.Errorf("前進後置換表的節點數%d沒有少於前進前的%d", len(table), before)
+	}
+	for hash, node := range table {
+		if !reachable[node] {
+			t.Errorf("置換表保留了新根節點無法到達的節點(雜湊值%d)", hash)
+		}
+		if node.state.(*fillGame).board[0] != 1 {
+			t.Errorf("置換表保留了玩家1沒有下在0的局面 %v", node.state.(*fillGame).board)
+		}
+	}
+}
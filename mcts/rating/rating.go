@@ -0,0 +1,180 @@
+package rating
+
+// Elo積分榜：登記的玩家兩兩循環對戰，每局結束後更新Elo分數，並存到檔案中累積
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+
+	arena "mcts/arena"
+	mcts "mcts/mcts"
+)
+
+const (
+	DefaultRating = 1500.0 // 新玩家的初始分數
+	DefaultK      = 32.0   // 每局分數變動的上限係數
+)
+
+// 玩家在積分榜上的紀錄
+type Entry struct {
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+}
+
+// Elo積分榜
+type Ladder struct {
+	K       float64           `json:"k"`
+	Initial float64           `json:"initial"`
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// 建立空的積分榜
+func NewLadder() *Ladder {
+	return &Ladder{
+		K:       DefaultK,
+		Initial: DefaultRating,
+		Entries: make(map[string]*Entry),
+	}
+}
+
+// 從檔案(json格式)讀取積分榜，檔案不存在時返回空的積分榜
+func LoadLadder(filename string) (*Ladder, error) {
+	data, err := ioutil.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewLadder(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ladder := NewLadder()
+	if err := json.Unmarshal(data, ladder); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if ladder.Entries == nil {
+		ladder.Entries = make(map[string]*Entry)
+	}
+	return ladder, nil
+}
+
+// 將積分榜寫入檔案(json格式)
+func (l *Ladder) Save(filename string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// 登記玩家，已登記的玩家保留原本的分數
+func (l *Ladder) Register(name string) *Entry {
+	entry, ok := l.Entries[name]
+	if !ok {
+		entry = &Entry{Name: name, Rating: l.Initial}
+		l.Entries[name] = entry
+	}
+	return entry
+}
+
+// a對b的期望得分
+func ExpectedScore(ratingA, ratingB float64) float64 {
+	return 1 / (1 + math.Pow(10, (ratingB-ratingA)/400))
+}
+
+// 依一局的結果(以a的角度)更新雙方的分數
+func (l *Ladder) Update(a, b string, outcome arena.Outcome) {
+	entryA, entryB := l.Register(a), l.Register(b)
+	scoreA := 0.5
+	switch outcome {
+	case arena.Win:
+		scoreA = 1
+		entryA.Wins++
+		entryB.Losses++
+	case arena.Loss:
+		scoreA = 0
+		entryA.Losses++
+		entryB.Wins++
+	default:
+		entryA.Draws++
+		entryB.Draws++
+	}
+	entryA.Games++
+	entryB.Games++
+
+	expectedA := ExpectedScore(entryA.Rating, entryB.Rating)
+	delta := l.K * (scoreA - expectedA)
+	entryA.Rating += delta
+	entryB.Rating -= delta
+}
+
+// 積分榜以名稱區分玩家，登記了同名的玩家時RoundRobin返回此錯誤
+var ErrDuplicatePlayer = errors.New("rating: 玩家名稱重複")
+
+// 所有玩家兩兩對戰gamesPerPair局(雙方輪流先手)，每局結束後更新分數
+// 有兩個玩家同名時不進行任何對戰並返回ErrDuplicatePlayer，避免同一筆紀錄同時記上勝負
+func (l *Ladder) RoundRobin(newGame func() mcts.Game, players []arena.Player, gamesPerPair int) error {
+	seen := make(map[string]bool)
+	for _, player := range players {
+		if seen[player.Name()] {
+			return fmt.Errorf("%w: %s", ErrDuplicatePlayer, player.Name())
+		}
+		seen[player.Name()] = true
+	}
+	for _, player := range players {
+		l.Register(player.Name())
+	}
+	for i := 0; i < len(players); i++ {
+		for j := i + 1; j < len(players); j++ {
+			a, b := players[i], players[j]
+			_, err := arena.Play(newGame, a, b, arena.Options{
+				Games: gamesPerPair,
+				OnGame: func(record arena.GameRecord) {
+					l.Update(a.Name(), b.Name(), record.Outcome)
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("%s vs %s: %w", a.Name(), b.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// 依分數由高到低排列的積分榜
+func (l *Ladder) Leaderboard() []Entry {
+	entries := make([]Entry, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// 以文字格式輸出積分榜
+func (l *Ladder) WriteLeaderboard(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%-4s %-30s %7s %6s %5s %5s %5s\n", "排名", "玩家", "分數", "局數", "勝", "和", "負"); err != nil {
+		return err
+	}
+	for i, entry := range l.Leaderboard() {
+		_, err := fmt.Fprintf(w, "%-4d %-30s %7.1f %6d %5d %5d %5d\n", i+1, entry.Name, entry.Rating, entry.Games, entry.Wins, entry.Draws, entry.Losses)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
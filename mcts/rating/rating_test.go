@@ -0,0 +1,50 @@
+package rating
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	arena "mcts/arena"
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+)
+
+func newGame() mcts.Game {
+	return tictactoe.New()
+}
+
+func TestRoundRobinRejectsDuplicateNames(t *testing.T) {
+	ladder := NewLadder()
+	players := []arena.Player{
+		arena.RandomPlayer{Rand: rand.New(rand.NewSource(1))},
+		tictactoe.PerfectPlayer{Rand: rand.New(rand.NewSource(2))},
+		arena.RandomPlayer{Rand: rand.New(rand.NewSource(3))},
+	}
+	err := ladder.RoundRobin(newGame, players, 2)
+	if !errors.Is(err, ErrDuplicatePlayer) {
+		t.Fatalf("同名玩家的錯誤為 %v，預期為 %v", err, ErrDuplicatePlayer)
+	}
+	if len(ladder.Entries) != 0 {
+		t.Errorf("名稱重複時不應該登記或更新任何玩家: %v", ladder.Entries)
+	}
+}
+
+func TestRoundRobinUpdatesBothPlayers(t *testing.T) {
+	ladder := NewLadder()
+	players := []arena.Player{
+		arena.RandomPlayer{Rand: rand.New(rand.NewSource(1))},
+		tictactoe.PerfectPlayer{Rand: rand.New(rand.NewSource(2))},
+	}
+	if err := ladder.RoundRobin(newGame, players, 10); err != nil {
+		t.Fatal(err)
+	}
+	random, perfect := ladder.Entries["random"], ladder.Entries["minimax"]
+	if random.Games != 10 || perfect.Games != 10 || random.Wins != perfect.Losses || random.Losses != perfect.Wins {
+		t.Errorf("雙方的紀錄不一致: %+v / %+v", *random, *perfect)
+	}
+	if perfect.Rating <= random.Rating || math.Abs(random.Rating+perfect.Rating-2*DefaultRating) > 1e-9 {
+		t.Errorf("完美玩家的分數應該較高且總分不變: %.1f / %.1f", random.Rating, perfect.Rating)
+	}
+}
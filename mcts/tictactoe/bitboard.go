@@ -0,0 +1,136 @@
+package tictactoe
+
+import (
+	"math/bits"
+
+	mcts "mcts/mcts"
+)
+
+// 以位元表示的棋況：masks[0]、masks[1]分別是玩家1、玩家2的棋子，第pos個位元代表位置pos
+// 下棋時同步更新步數與Zobrist雜湊值，判斷輸贏只需要和贏線遮罩做AND
+type Bitboard struct {
+	masks      [2]uint16
+	moves      int    // 已下的步數
+	hash       uint64 // Zobrist雜湊值，與GameState.Hash相同
+	LastPlaced int
+}
+
+const fullMask uint16 = 1<<9 - 1
+
+// 每條贏線對應的遮罩
+var winMasks = func() (masks [8]uint16) {
+	for i, line := range WinLines {
+		for _, pos := range line {
+			masks[i] |= 1 << pos
+		}
+	}
+	return masks
+}()
+
+var (
+	_ mcts.Game   = (*Bitboard)(nil)
+	_ mcts.Hasher = (*Bitboard)(nil)
+)
+
+// 建立新的一局位元棋況
+func NewBitboard() *Bitboard {
+	return &Bitboard{LastPlaced: -1}
+}
+
+// 將GameState轉成位元棋況
+func BitboardFrom(state *GameState) *Bitboard {
+	b := &Bitboard{LastPlaced: state.LastPlaced, hash: state.Hash()}
+	for pos, player := range state.Board {
+		if player != None {
+			b.masks[player-1] |= 1 << pos
+			b.moves++
+		}
+	}
+	return b
+}
+
+// 轉回GameState
+func (b *Bitboard) GameState() *GameState {
+	state := &GameState{LastPlaced: b.LastPlaced}
+	for pos := range state.Board {
+		state.Board[pos] = b.At(pos)
+	}
+	return state
+}
+
+// 取得位置pos的棋子
+func (b *Bitboard) At(pos int) int {
+	bit := uint16(1) << pos
+	switch {
+	case b.masks[0]&bit != 0:
+		return Player1
+	case b.masks[1]&bit != 0:
+		return Player2
+	}
+	return None
+}
+
+// 已下的步數
+func (b *Bitboard) Moves() int {
+	return b.moves
+}
+
+// 空格的遮罩
+func (b *Bitboard) empty() uint16 {
+	return ^(b.masks[0] | b.masks[1]) & fullMask
+}
+
+// 取得目前可行動的位置(實作mcts.Game)
+func (b *Bitboard) GetLegalMoves() []int {
+	moves := make([]int, 0, 9-b.moves)
+	for empty := b.empty(); empty != 0; empty &= empty - 1 {
+		moves = append(moves, bits.TrailingZeros16(empty))
+	}
+	return moves
+}
+
+// 由目前玩家在pos放置棋子(實作mcts.Game)
+func (b *Bitboard) ApplyMove(pos int) {
+	player := b.CurrentPlayer()
+	b.masks[player-1] |= 1 << pos
+	b.moves++
+	b.hash ^= zobristKeys[pos][player]
+	b.LastPlaced = pos
+}
+
+// 複製棋況(實作mcts.Game)
+func (b *Bitboard) Clone() mcts.Game {
+	newState := *b
+	return &newState
+}
+
+// 取得目前換哪位玩家行動(實作mcts.Game)，已下偶數步時換玩家1
+func (b *Bitboard) CurrentPlayer() int {
+	if b.moves&1 == 0 {
+		return Player1
+	}
+	return Player2
+}
+
+// 取得棋局是否結束與贏家(實作mcts.Game)
+func (b *Bitboard) Result() (bool, int) {
+	for _, line := range winMasks {
+		if b.masks[0]&line == line {
+			return true, Player1
+		}
+		if b.masks[1]&line == line {
+			return true, Player2
+		}
+	}
+	return b.moves == 9, None
+}
+
+// 局面的雜湊值(實作mcts.Hasher)，下棋時已同步更新
+func (b *Bitboard) Hash() uint64 {
+	return b.hash
+}
+
+// 畫出棋況結果圖
+func (b *Bitboard) DrawTable() string {
+	return b.GameState().DrawTable()
+}
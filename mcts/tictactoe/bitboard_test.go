@@ -0,0 +1,57 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	gametest "mcts/gametest"
+	mcts "mcts/mcts"
+)
+
+// 隨機對局中Bitboard每一步都要跟GameState有相同的盤面、步數、合法動作、雜湊值與結果
+func TestBitboardMatchesGameState(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	check := func(ply int, a, b mcts.Game) error {
+		state, board := a.(*GameState), b.(*Bitboard)
+		if converted := board.GameState(); converted.Board != state.Board || converted.LastPlaced != state.LastPlaced {
+			return fmt.Errorf("盤面不同:\n%s/\n%s", state.DrawTable(), board.DrawTable())
+		}
+		if board.Moves() != ply {
+			return fmt.Errorf("步數為%d，預期為%d", board.Moves(), ply)
+		}
+		return nil
+	}
+	for i := 0; i < 1000; i++ {
+		if err := gametest.CompareRandomGame(rng, New(), NewBitboard(), check); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBitboardFromGameState(t *testing.T) {
+	state := New()
+	for _, pos := range []int{4, 0, 8} {
+		state.Play(pos)
+	}
+	board := BitboardFrom(state)
+	if board.Hash() != state.Hash() || board.Moves() != 3 || board.CurrentPlayer() != Player2 || *board.GameState() != (GameState{Board: state.Board, LastPlaced: 8}) {
+		t.Errorf("BitboardFrom沒有保留棋況: %+v", board)
+	}
+}
+
+func BenchmarkPlayoutGameState(b *testing.B) {
+	gametest.BenchmarkRandomPlayouts(b, func() mcts.Game { return New() })
+}
+
+func BenchmarkPlayoutBitboard(b *testing.B) {
+	gametest.BenchmarkRandomPlayouts(b, func() mcts.Game { return NewBitboard() })
+}
+
+func BenchmarkSearchGameState(b *testing.B) {
+	gametest.BenchmarkSearch(b, func() mcts.Game { return New() }, 10000)
+}
+
+func BenchmarkSearchBitboard(b *testing.B) {
+	gametest.BenchmarkSearch(b, func() mcts.Game { return NewBitboard() }, 10000)
+}
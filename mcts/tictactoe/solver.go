@@ -0,0 +1,159 @@
+package tictactoe
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	mcts "mcts/mcts"
+)
+
+// 棋局的賽局理論值，以輪到行動的一方的角度表示
+const (
+	Loss = -1
+	Draw = 0
+	Win  = 1
+)
+
+// 棋局在雙方都完美下棋時的解
+type Solution struct {
+	Value        int   // 賽局理論值(Win/Draw/Loss)
+	OptimalMoves []int // 能保持Value的所有位置，棋局已結束時為空
+}
+
+// 完美下棋的解算器，用minimax(negamax)走訪整棵遊戲樹並把每個棋局的解快取起來
+// 井字棋可到達的棋局只有5478種，第一次使用時就把整個棋局空間解完
+type Solver struct {
+	mu    sync.Mutex // 查詢不在快取中的棋局時會寫入快取，讓共用的解算器可以在多個goroutine中使用
+	cache map[[9]int]Solution
+}
+
+var (
+	defaultSolver     *Solver
+	defaultSolverOnce sync.Once
+)
+
+// 取得共用的解算器(第一次呼叫時解完整個棋局空間，之後只查表)
+func DefaultSolver() *Solver {
+	defaultSolverOnce.Do(func() {
+		defaultSolver = NewSolver()
+	})
+	return defaultSolver
+}
+
+// 建立解算器並從空棋盤開始解完所有可到達的棋局
+func NewSolver() *Solver {
+	s := &Solver{cache: make(map[[9]int]Solution)}
+	s.solve(New())
+	return s
+}
+
+// 取得棋局的解，不在快取中的棋局(例如不合法的擺法)會當場計算並加入快取
+// 返回的OptimalMoves是複本，呼叫者可以自由修改而不影響快取
+func (s *Solver) Solve(t *GameState) Solution {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := *t // solve會在棋況上Play/Undo，複製一份避免動到呼叫者的棋況
+	solution := s.solve(&state)
+	solution.OptimalMoves = append([]int(nil), solution.OptimalMoves...)
+	return solution
+}
+
+// 取得棋局以目前玩家角度的賽局理論值
+func (s *Solver) Value(t *GameState) int {
+	return s.Solve(t).Value
+}
+
+// 取得所有最佳位置
+func (s *Solver) OptimalMoves(t *GameState) []int {
+	return s.Solve(t).OptimalMoves
+}
+
+// 判斷在pos放置棋子是否為最佳下法
+func (s *Solver) IsOptimal(t *GameState, pos int) bool {
+	for _, move := range s.Solve(t).OptimalMoves {
+		if move == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// 已快取的棋局數量
+func (s *Solver) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.cache)
+}
+
+// negamax：子棋局的值取負號就是目前玩家的值，取最大值的所有位置都是最佳下法
+// 走訪子棋局時在同一個棋況上Play/Undo，返回時state與傳入時相同
+func (s *Solver) solve(state *GameState) Solution {
+	board := state.Board
+	if solution, ok := s.cache[board]; ok {
+		return solution
+	}
+
+	result := state.GetGameState()
+	if result.IsTerminal {
+		// 棋局結束時贏家一定是上一步下棋的玩家，對目前玩家來說是輸
+		solution := Solution{Value: Draw}
+		if result.Winner != None {
+			solution.Value = Loss
+		}
+		s.cache[board] = solution
+		return solution
+	}
+
+	solution := Solution{Value: Loss - 1}
+	for _, pos := range state.GetLegalPosz() {
+		state.Play(pos)
+		value := -s.solve(state).Value
+		state.Undo()
+		if value > solution.Value {
+			solution.Value = value
+			solution.OptimalMoves = []int{pos}
+		} else if value == solution.Value {
+			solution.OptimalMoves = append(solution.OptimalMoves, pos)
+		}
+	}
+	s.cache[board] = solution
+	return solution
+}
+
+// 完美下棋的玩家(可用於mcts/arena)，從所有最佳位置中隨機選一個
+type PerfectPlayer struct {
+	Solver *Solver    // nil時使用DefaultSolver
+	Rand   *rand.Rand // nil時使用共用的亂數來源(第一次使用時以目前時間為種子)
+}
+
+func (p PerfectPlayer) Name() string {
+	return "minimax"
+}
+
+func (p PerfectPlayer) ChooseMove(game mcts.Game) int {
+	solver := p.Solver
+	if solver == nil {
+		solver = DefaultSolver()
+	}
+	moves := solver.OptimalMoves(game.(*GameState))
+	return moves[intn(p.Rand, len(moves))]
+}
+
+var (
+	sharedRandMu sync.Mutex
+	sharedRand   *rand.Rand // 玩家沒有指定Rand時共用的亂數來源，第一次使用時以目前時間為種子
+)
+
+// 從rng取[0,n)的亂數，rng為nil時使用共用的亂數來源(可以在多個goroutine中同時呼叫)
+func intn(rng *rand.Rand, n int) int {
+	if rng != nil {
+		return rng.Intn(n)
+	}
+	sharedRandMu.Lock()
+	defer sharedRandMu.Unlock()
+	if sharedRand == nil {
+		sharedRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return sharedRand.Intn(n)
+}
@@ -0,0 +1,25 @@
+package tictactoe
+
+import "testing"
+
+func TestSolveReturnsCopy(t *testing.T) {
+	solver := NewSolver()
+	moves := solver.OptimalMoves(New())
+	for i := range moves {
+		moves[i] = -1
+	}
+	for _, move := range solver.OptimalMoves(New()) {
+		if move < 0 {
+			t.Fatalf("修改返回的OptimalMoves改到了快取: %v", solver.OptimalMoves(New()))
+		}
+	}
+}
+
+func TestPerfectPlayerZeroValue(t *testing.T) {
+	game := New()
+	game.Play(0)
+	move := PerfectPlayer{}.ChooseMove(game)
+	if move != 4 {
+		t.Errorf("對手下角落時唯一的最佳下法是中央，實際選擇%d", move)
+	}
+}
@@ -1,11 +1,26 @@
 package tictactoe
 
+import (
+	"errors"
+	"fmt"
+
+	mcts "mcts/mcts"
+)
+
 const (
 	None    = 0
 	Player1 = 1
 	Player2 = 2
 )
 
+// PlayMove檢查到的不合法動作
+var (
+	ErrOutOfRange  = errors.New("tictactoe: 位置超出範圍(0-8)")
+	ErrOccupied    = errors.New("tictactoe: 該位置已被佔用")
+	ErrGameOver    = errors.New("tictactoe: 棋局已結束")
+	ErrWrongPlayer = errors.New("tictactoe: 還沒輪到該玩家")
+)
+
 // 棋局結果
 type GameResult struct {
 	IsTerminal bool
@@ -28,6 +43,8 @@ var WinLines = [][3]int{
 type GameState struct {
 	Board      [9]int
 	LastPlaced int
+	history    [9]int // 每一步Play之前的LastPlaced，供Undo還原
+	plies      int    // history中的步數
 }
 
 // 建立新的一局棋況
@@ -74,7 +91,83 @@ func (t *GameState) GetLegalPosz() []int {
 	return emptyPosz
 }
 
-// 取消動作
+// 取得目前可行動的位置(實作mcts.Game)
+func (t *GameState) GetLegalMoves() []int {
+	return t.GetLegalPosz()
+}
+
+// 由目前玩家在pos放置棋子(實作mcts.Game)
+func (t *GameState) ApplyMove(pos int) {
+	t.Play(pos)
+}
+
+// 由目前玩家在pos放置棋子並記錄在歷史中，之後可以用Undo還原
+// 深度優先走訪(例如Solver)時對同一個棋況Play/Undo就不需要為每個子節點複製棋況
+// Play不檢查輪到誰或棋局是否結束(需要檢查時使用PlayMove)，但在已有棋子的位置Play會panic，
+// 否則歷史會超過9步，Undo也會清掉原本的棋子
+func (t *GameState) Play(pos int) {
+	if t.Board[pos] != None {
+		panic(fmt.Sprintf("tictactoe: 位置%d已有棋子，不能Play", pos))
+	}
+	t.history[t.plies] = t.LastPlaced
+	t.plies++
+	t.Board[pos] = t.CurrentPlayer()
+	t.LastPlaced = pos
+}
+
+// 檢查player在pos放置棋子是否合法，合法時返回nil
+func (t *GameState) CheckMove(player, pos int) error {
+	if t.GetGameState().IsTerminal {
+		return ErrGameOver
+	}
+	if current := t.CurrentPlayer(); player != current {
+		return fmt.Errorf("%w: 目前輪到玩家%d", ErrWrongPlayer, current)
+	}
+	if pos < 0 || pos >= len(t.Board) {
+		return fmt.Errorf("%w: %d", ErrOutOfRange, pos)
+	}
+	if t.Board[pos] != None {
+		return fmt.Errorf("%w: %d", ErrOccupied, pos)
+	}
+	return nil
+}
+
+// 檢查後由player在pos放置棋子，不合法時棋況不變並返回ErrGameOver、ErrWrongPlayer、ErrOutOfRange或ErrOccupied
+func (t *GameState) PlayMove(player, pos int) error {
+	if err := t.CheckMove(player, pos); err != nil {
+		return err
+	}
+	t.Play(pos)
+	return nil
+}
+
+// 還原最後一次Play(包含LastPlaced)，沒有可還原的動作時返回false
+func (t *GameState) Undo() bool {
+	if t.plies == 0 {
+		return false
+	}
+	t.plies--
+	t.Board[t.LastPlaced] = None
+	t.LastPlaced = t.history[t.plies]
+	t.history[t.plies] = 0
+	return true
+}
+
+// 複製棋況(實作mcts.Game)
+func (t *GameState) Clone() mcts.Game {
+	newState := *t
+	return &newState
+}
+
+// 取得棋局是否結束與贏家(實作mcts.Game)
+func (t *GameState) Result() (bool, int) {
+	result := t.GetGameState()
+	return result.IsTerminal, result.Winner
+}
+
+// 取消動作，只清除該位置，不會還原LastPlaced
+//
+// Deprecated: 使用Undo還原Play的動作
 func (t *GameState) UndoAction(pos int) {
 	t.Board[pos] = None
 }
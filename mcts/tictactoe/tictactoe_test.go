@@ -0,0 +1,127 @@
+package tictactoe
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// 確認兩個棋況的Board、LastPlaced與歷史步數都相同
+func assertSameState(t *testing.T, got, want *GameState, context string) {
+	t.Helper()
+	if got.Board != want.Board || got.LastPlaced != want.LastPlaced || got.plies != want.plies {
+		t.Fatalf("%s: 棋況為 %v(LastPlaced=%d plies=%d)，預期為 %v(LastPlaced=%d plies=%d)",
+			context, got.Board, got.LastPlaced, got.plies, want.Board, want.LastPlaced, want.plies)
+	}
+}
+
+// 隨機下棋時，每一步Play之後立刻Undo都要回到原本的棋況
+func TestPlayUndoIsIdentity(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for game := 0; game < 1000; game++ {
+		state := New()
+		for {
+			moves := state.GetLegalMoves()
+			if terminal, _ := state.Result(); terminal || len(moves) == 0 {
+				break
+			}
+			before := *state
+			move := moves[rng.Intn(len(moves))]
+			state.Play(move)
+			if !state.Undo() {
+				t.Fatalf("Play(%d)之後Undo返回false", move)
+			}
+			assertSameState(t, state, &before, "Play之後Undo")
+			if *state != before {
+				t.Fatalf("Play(%d)之後Undo沒有還原歷史", move)
+			}
+			state.Play(move)
+		}
+	}
+}
+
+// 隨機下完整局(包含分出勝負後繼續下到滿)，再依序Undo到空棋盤，每一步都要回到當時的棋況
+func TestUndoRestoresWholeHistory(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for game := 0; game < 1000; game++ {
+		state := New()
+		snapshots := []GameState{*state}
+		for _, move := range rng.Perm(9) {
+			state.Play(move)
+			snapshots = append(snapshots, *state)
+		}
+		for i := len(snapshots) - 2; i >= 0; i-- {
+			if !state.Undo() {
+				t.Fatalf("第%d步的Undo返回false", i+1)
+			}
+			assertSameState(t, state, &snapshots[i], "依序Undo")
+		}
+		if state.Undo() {
+			t.Fatal("空棋盤的Undo應該返回false")
+		}
+		assertSameState(t, state, New(), "Undo到底")
+	}
+}
+
+// 直接指定盤面建立的棋況沒有歷史，Undo只能還原之後Play的動作
+func TestUndoFromPositionWithoutHistory(t *testing.T) {
+	state := &GameState{Board: [9]int{Player1, Player2, 0, 0, 0, 0, 0, 0, 0}, LastPlaced: 1}
+	before := *state
+	state.Play(4)
+	if !state.Undo() || *state != before {
+		t.Fatalf("Undo沒有還原到指定的盤面: %+v", state)
+	}
+	if state.Undo() {
+		t.Fatal("沒有歷史的盤面Undo應該返回false")
+	}
+}
+
+func TestPlayOccupiedPanics(t *testing.T) {
+	state := New()
+	state.Play(4)
+	defer func() {
+		if recover() == nil {
+			t.Error("在已有棋子的位置Play應該panic")
+		}
+	}()
+	state.Play(4)
+}
+
+func TestPlayMoveErrors(t *testing.T) {
+	won := New()
+	for _, pos := range []int{0, 3, 1, 4, 2} { // 玩家1連成第一列
+		won.Play(pos)
+	}
+	started := New()
+	started.Play(4)
+
+	tests := []struct {
+		name   string
+		state  *GameState
+		player int
+		pos    int
+		want   error
+	}{
+		{"超出範圍", New(), Player1, 9, ErrOutOfRange},
+		{"負的位置", New(), Player1, -1, ErrOutOfRange},
+		{"已被佔用", started, Player2, 4, ErrOccupied},
+		{"還沒輪到", started, Player1, 0, ErrWrongPlayer},
+		{"棋局已結束", won, Player2, 8, ErrGameOver},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := *test.state
+			if err := test.state.PlayMove(test.player, test.pos); !errors.Is(err, test.want) {
+				t.Errorf("PlayMove(%d, %d)的錯誤為 %v，預期為 %v", test.player, test.pos, err, test.want)
+			}
+			if *test.state != before {
+				t.Error("不合法的PlayMove改變了棋況")
+			}
+		})
+	}
+
+	state := New()
+	if err := state.PlayMove(Player1, 4); err != nil || state.Board[4] != Player1 || state.LastPlaced != 4 {
+		t.Errorf("合法的PlayMove失敗: %v %+v", err, state)
+	}
+}
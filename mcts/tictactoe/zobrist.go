@@ -0,0 +1,29 @@
+package tictactoe
+
+import (
+	"math/rand"
+
+	mcts "mcts/mcts"
+)
+
+// Zobrist hashing：每個位置的每種棋子各有一個固定的隨機值，局面的雜湊值是盤面上所有棋子對應值的XOR
+var zobristKeys = func() (keys [9][3]uint64) {
+	rng := rand.New(rand.NewSource(9))
+	for pos := range keys {
+		for player := Player1; player <= Player2; player++ {
+			keys[pos][player] = rng.Uint64()
+		}
+	}
+	return keys
+}()
+
+var _ mcts.Hasher = (*GameState)(nil)
+
+// 局面的雜湊值(實作mcts.Hasher)，輪到的玩家由棋子數決定，不需要另外加入
+func (t *GameState) Hash() uint64 {
+	var hash uint64
+	for pos, player := range t.Board {
+		hash ^= zobristKeys[pos][player]
+	}
+	return hash
+}
@@ -0,0 +1,158 @@
+package agent
+
+// Q-學習(Q-learning)是強化學習的一種方法。Q-學習就是要記錄下學習過的策略，因而告訴智能體什麼情況下採取什麼行動會有最大的獎勵值
+// 「Q」這個字母在強化學習中表示一個動作的期望獎勵
+// Q:S(環境狀態)xA(行為)->R(獎勵)
+
+import (
+	"math"
+	"math/rand"
+
+	"tdlearning/ticTacToe"
+)
+
+// Q-learning的超參數
+type Config struct {
+	LearningRate         float64 `json:"learningRate" yaml:"learningRate"`                 // 學習率 大於0,小於等於1 較高的學習率會較快學習新策略，反之agent會比較傾向已經學到的策略
+	DiscountFactor       float64 `json:"discountFactor" yaml:"discountFactor"`             // 折扣係數 0~1  當discountFactor數值越大時agent更加重視未來獲得的長期獎勵，discountFactor數值越小時，更加短視近利，只在乎目前可獲得的獎勵
+	ExplorationRate      float64 `json:"explorationRate" yaml:"explorationRate"`           // 探索率(貪婪策略) 也就是agent選擇要探索還是利用的機率 範圍0~1 0代表不學習了只依賴目前Q表中的最佳策略(利用)
+	ExplorationDecayRate float64 `json:"explorationDecayRate" yaml:"explorationDecayRate"` // 探索率衰減 每次遊戲結束時 explorationRate會乘上此值來降低下一局的探索率
+	Rewards              Rewards `json:"rewards" yaml:"rewards"`                           // 棋局結束時行動方得到的獎勵
+}
+
+// 預設的超參數
+func DefaultConfig() Config {
+	return Config{
+		LearningRate:         0.5,
+		DiscountFactor:       0.7,
+		ExplorationRate:      1.0,
+		ExplorationDecayRate: 0.99997, // 自我對弈需要較長時間的探索，訓練10萬局後探索率約為0.05
+		Rewards:              DefaultRewards(),
+	}
+}
+
+// Q-learning智能體，持有自己的Q表、超參數與亂數來源
+// Q表中每個棋局的Q值都是以該棋局輪到的一方(行動方)的角度記錄，所以同一張Q表可以同時下圈圈與叉叉
+type QAgent struct {
+	Table           ticTacToe.QTable
+	Config          Config
+	Token           int // 代表agent的棋子(1:圈圈 2:叉叉)，用來統計勝負
+	Episodes        int // 已訓練的遊戲次數
+	explorationRate float64
+	rng             *rand.Rand
+}
+
+// 建立智能體，table為nil時使用新初始化的Q表
+func New(table ticTacToe.QTable, config Config, rng *rand.Rand) *QAgent {
+	if table == nil {
+		table = ticTacToe.InitQTable()
+	}
+	return &QAgent{
+		Table:           table,
+		Config:          config,
+		Token:           1,
+		explorationRate: config.ExplorationRate,
+		rng:             rng,
+	}
+}
+
+// 目前的探索率
+func (a *QAgent) ExplorationRate() float64 {
+	return a.explorationRate
+}
+
+// 依據Q表與目前探索率選擇行動
+func (a *QAgent) Act(state ticTacToe.State) int {
+	return a.chooseAction(state, a.explorationRate)
+}
+
+// 不探索，直接選擇Q表中價值最高的行動
+func (a *QAgent) BestAction(state ticTacToe.State) int {
+	return a.greedyAction(state)
+}
+
+// 傳入目前棋況並依據Q表與探索率來行動
+func (a *QAgent) chooseAction(state ticTacToe.State, explorationRate float64) int {
+	//隨機值如果小於探索率，則進行探索(隨機選擇一個合法行動)
+	if a.rng.Float64() < explorationRate {
+		return RandomAction(state, a.rng)
+	}
+	//否則，選擇最大Q值的行動(利用)
+	return a.greedyAction(state)
+}
+
+// 選擇最大Q值的合法行動，Q表中沒有該棋況時返回-1
+// 只讀取Q表、不使用亂數，Q表不再更新時可以在多個goroutine中同時呼叫
+func (a *QAgent) greedyAction(state ticTacToe.State) int {
+	//從Q表中獲取當前棋況的行動值(Q表以標準形儲存，行動位置需要轉換回目前棋盤)
+	canonical, symmetry := ticTacToe.Canonicalize(state)
+	actionValues := a.Table[canonical]
+	myAction := -1
+	bestValue := math.Inf(-1)
+	//依動作編號順序比較(不走訪map)，同分時固定選編號最小的，讓相同的亂數種子得到相同的結果
+	for canonicalAction := 0; canonicalAction < len(state); canonicalAction++ {
+		value, ok := actionValues[canonicalAction]
+		if !ok {
+			continue
+		}
+		action := symmetry.FromCanonical(canonicalAction)
+		if value > bestValue && state[action] == 0 {
+			bestValue = value
+			myAction = action
+		}
+	}
+	return myAction
+}
+
+// 由token執行行動並以token(行動方)的角度更新Q表，返回新狀態
+func (a *QAgent) Observe(token int, state ticTacToe.State, action int) ticTacToe.State {
+	nextState, _ := DoAction(token, state, action)
+	reward := a.Config.Rewards.Of(CheckGameState(token, nextState))
+	a.Update(state, action, nextState, reward)
+	return nextState
+}
+
+// 更新Q表，reward為行動方得到的獎勵
+// 採用negamax形式的TD目標：下一個棋局輪到對手，對手能得到的最佳價值就是行動方的損失
+// Q(s,a) <- Q(s,a) + α(r - γ·maxQ(s') - Q(s,a))，棋局結束時目標只有r
+func (a *QAgent) Update(state ticTacToe.State, action int, nextState ticTacToe.State, reward float64) {
+	//轉換成標準形後再查表
+	canonical, symmetry := ticTacToe.Canonicalize(state)
+	canonicalAction := symmetry.ToCanonical(action)
+	//時序差分學習(Temporal-Difference Learning，簡稱TD Learning)
+	target := reward
+	if finished, _ := ticTacToe.IsGameFinished(nextState); !finished {
+		target -= a.Config.DiscountFactor * a.maxQ(nextState)
+	}
+	oldValue := a.Table[canonical][canonicalAction]
+	a.Table[canonical][canonicalAction] = oldValue + a.Config.LearningRate*(target-oldValue)
+}
+
+// 依照Q表中取得目前棋況最高價值的行動價值
+func (a *QAgent) maxQ(state ticTacToe.State) float64 {
+	canonical, _ := ticTacToe.Canonicalize(state) //最大值與行動位置無關，不需要轉換行動
+	actionQ, ok := a.Table[canonical]
+	if !ok || len(actionQ) == 0 { //已結束的棋局不會有下一步的Q值資料，此時返回0
+		return 0
+	}
+
+	maxQ := math.Inf(-1)
+	for _, q := range actionQ {
+		if q > maxQ {
+			maxQ = q
+		}
+	}
+	return maxQ
+}
+
+// 無策略下棋方法，隨機選擇一個合法行動
+func RandomAction(state ticTacToe.State, rng *rand.Rand) int {
+	legalActions := make([]int, 0)
+	for i, value := range state {
+		if value == 0 {
+			legalActions = append(legalActions, i)
+		}
+	}
+
+	return legalActions[rng.Intn(len(legalActions))]
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// 固定種子自我對弈訓練後，agent應該在所有棋局都選到最佳行動，且不會輸給完美玩家
+func TestSelfPlayConvergesToPerfectPlay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("自我對弈訓練需要約1秒")
+	}
+	const episodes = 100000
+	qAgent := New(nil, DefaultConfig(), rand.New(rand.NewSource(1)))
+	qAgent.Train(episodes, 0, nil)
+
+	if optimality := qAgent.Optimality(); optimality != 1 {
+		t.Errorf("訓練%d局後最佳行動比例為%.4f，預期為1", episodes, optimality)
+	}
+	stats := qAgent.EvaluateAgainst(qAgent.PerfectPolicy(), 1000)
+	if stats.Loses != 0 {
+		t.Errorf("跟完美玩家%d局對戰中輸了%d局，預期不會輸", stats.Games, stats.Loses)
+	}
+}
+
+// 相同種子訓練出來的Q表要完全相同
+func TestSameSeedSameTable(t *testing.T) {
+	train := func() *QAgent {
+		qAgent := New(nil, DefaultConfig(), rand.New(rand.NewSource(7)))
+		qAgent.Train(5000, 0, nil)
+		return qAgent
+	}
+	first, second := train(), train()
+	if !reflect.DeepEqual(first.Table, second.Table) {
+		t.Error("相同種子訓練出來的Q表不同")
+	}
+	if first.ExplorationRate() != second.ExplorationRate() || first.Episodes != second.Episodes {
+		t.Error("相同種子訓練後的探索率或訓練次數不同")
+	}
+}
@@ -0,0 +1,81 @@
+package agent
+
+import "tdlearning/ticTacToe"
+
+type GameState int //遊戲狀態
+const (
+	NotFinish GameState = iota
+	Win
+	Lose
+	Draw
+)
+
+func (gs GameState) String() string {
+	names := [...]string{
+		"未結束的棋局",
+		"贏",
+		"輸",
+		"平手",
+	}
+
+	if gs < NotFinish || gs > Draw {
+		return "unknown"
+	}
+
+	return names[gs]
+}
+
+// 棋局結束時行動方得到的獎勵
+type Rewards struct {
+	Win  float64 `json:"win" yaml:"win"`
+	Draw float64 `json:"draw" yaml:"draw"`
+	Loss float64 `json:"loss" yaml:"loss"`
+}
+
+// 預設獎勵 勝利1 平手0 失敗-1
+func DefaultRewards() Rewards {
+	return Rewards{Win: 1, Draw: 0, Loss: -1}
+}
+
+// 依據遊戲狀態取得獎勵，棋局未結束時為0
+func (r Rewards) Of(result GameState) float64 {
+	switch result {
+	case Win:
+		return r.Win
+	case Lose:
+		return r.Loss
+	case Draw:
+		return r.Draw
+	}
+	return 0
+}
+
+// 執行選擇的動作，reward為token這一方以預設獎勵計算的獎勵
+func DoAction(token int, state ticTacToe.State, action int) (newState ticTacToe.State, reward float64) {
+	newState = state //陣列可以這樣 但如果ticTacToe.State是宣告為slice就要用深複製
+	//執行行動，將棋子放置在選定的位置
+	newState[action] = token
+
+	//檢查遊戲結果並根據遊戲結果設定獎勵值
+	reward = DefaultRewards().Of(CheckGameState(token, newState))
+
+	return newState, reward
+}
+
+// 依據棋況返回token這一方的遊戲狀態GameState
+func CheckGameState(token int, state ticTacToe.State) GameState {
+
+	// 棋局尚未結束判斷
+	isGameFinished, winningToken := ticTacToe.IsGameFinished(state)
+	if !isGameFinished {
+		return NotFinish
+	}
+	// 有任一方贏了
+	if winningToken == 0 {
+		return Draw
+	} else if winningToken == token {
+		return Win
+	} else {
+		return Lose
+	}
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"math/rand"
+
+	tictactoe "mcts/tictactoe"
+	"tdlearning/ticTacToe"
+)
+
+// 轉換成mcts/tictactoe的棋況，以便使用完美下棋的解算器
+func toGameState(state ticTacToe.State) *tictactoe.GameState {
+	return &tictactoe.GameState{Board: state, LastPlaced: -1}
+}
+
+// 棋局以輪到的一方角度的賽局理論值(tictactoe.Win/Draw/Loss)
+func SolvedValue(state ticTacToe.State) int {
+	return tictactoe.DefaultSolver().Value(toGameState(state))
+}
+
+// 棋局的所有最佳位置
+func OptimalActions(state ticTacToe.State) []int {
+	return tictactoe.DefaultSolver().OptimalMoves(toGameState(state))
+}
+
+// 判斷在棋局中下action是否為最佳下法
+func IsOptimalAction(state ticTacToe.State, action int) bool {
+	return tictactoe.DefaultSolver().IsOptimal(toGameState(state), action)
+}
+
+// 完美下棋的策略，從所有最佳位置中隨機選一個
+func PerfectPolicy(rng *rand.Rand) Policy {
+	return func(state ticTacToe.State) int {
+		actions := OptimalActions(state)
+		return actions[rng.Intn(len(actions))]
+	}
+}
+
+// 隨機下棋的策略
+func RandomPolicy(rng *rand.Rand) Policy {
+	return func(state ticTacToe.State) int {
+		return RandomAction(state, rng)
+	}
+}
+
+// 使用agent亂數來源的隨機策略
+func (a *QAgent) RandomPolicy() Policy {
+	return RandomPolicy(a.rng)
+}
+
+// 使用agent亂數來源的完美策略
+func (a *QAgent) PerfectPolicy() Policy {
+	return PerfectPolicy(a.rng)
+}
+
+// agent在所有可到達且未結束的棋局中選到最佳行動的比例(0~1)，1代表已學會完美下棋
+func (a *QAgent) Optimality() float64 {
+	optimal, total := 0, 0
+	ticTacToe.EnumerateStates().ForEach(func(state ticTacToe.State, info ticTacToe.StateInfo) bool {
+		if info.IsTerminal {
+			return true
+		}
+		total++
+		if IsOptimalAction(state, a.BestAction(state)) {
+			optimal++
+		}
+		return true
+	})
+	return float64(optimal) / float64(total)
+}
@@ -0,0 +1,25 @@
+package agent
+
+import (
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+	"tdlearning/ticTacToe"
+)
+
+// 將agent包裝成mcts/arena的玩家(不探索)，只能用於mcts/tictactoe的井字棋
+type Player struct {
+	Agent *QAgent
+	Label string // 顯示名稱，空字串時為"q-agent"
+}
+
+func (p Player) Name() string {
+	if p.Label == "" {
+		return "q-agent"
+	}
+	return p.Label
+}
+
+func (p Player) ChooseMove(game mcts.Game) int {
+	state := ticTacToe.State(game.(*tictactoe.GameState).Board)
+	return p.Agent.BestAction(state)
+}
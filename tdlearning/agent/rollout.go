@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"math/rand"
+
+	mcts "mcts/mcts"
+	tictactoe "mcts/tictactoe"
+	"tdlearning/ticTacToe"
+)
+
+// 以Q表引導MCTS模擬(Rollout)階段的策略，只能用於mcts/tictactoe的井字棋
+// 搜尋時只讀取Q表，搜尋期間不要同時訓練同一個agent
+type Rollout struct {
+	Agent   *QAgent
+	Epsilon float64 // 隨機下棋的機率，保留模擬的多樣性
+}
+
+var _ mcts.RolloutPolicy = Rollout{}
+
+func (r Rollout) ChooseMove(game mcts.Game, moves []int, rng *rand.Rand) int {
+	if rng.Float64() >= r.Epsilon {
+		state := ticTacToe.State(game.(*tictactoe.GameState).Board)
+		if action := r.Agent.greedyAction(state); action >= 0 {
+			return action
+		}
+	}
+	//Q表中沒有的棋況(或探索時)改為隨機
+	return moves[rng.Intn(len(moves))]
+}
@@ -0,0 +1,129 @@
+package agent
+
+import "tdlearning/ticTacToe"
+
+// 一段對局期間的統計
+type Stats struct {
+	Games int
+	Wins  int
+	Loses int
+	Draws int
+}
+
+// 勝率(百分比)
+func (s Stats) WinRate() float64 {
+	return percent(s.Wins, s.Games)
+}
+
+// 失敗率(百分比)
+func (s Stats) LoseRate() float64 {
+	return percent(s.Loses, s.Games)
+}
+
+// 平手率(百分比)
+func (s Stats) DrawRate() float64 {
+	return percent(s.Draws, s.Games)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// 記錄一局的結果
+func (s *Stats) add(result GameState) {
+	s.Games++
+	switch result {
+	case Win:
+		s.Wins++
+	case Lose:
+		s.Loses++
+	case Draw:
+		s.Draws++
+	}
+}
+
+// 每隔一段訓練回報一次的內容
+type TrainReport struct {
+	From            int // 這段訓練的第一局(從1開始)
+	To              int // 這段訓練的最後一局
+	Stats           Stats
+	ExplorationRate float64
+}
+
+// 對手的下棋策略，傳入目前棋況返回要下的位置
+type Policy func(state ticTacToe.State) int
+
+// 自我對弈訓練episodes局，每reportInterval局呼叫一次report(report可為nil)，統計以agent.Token的角度計算
+func (a *QAgent) Train(episodes, reportInterval int, report func(TrainReport)) {
+	a.TrainAgainst(nil, episodes, reportInterval, report)
+}
+
+// 跟opponent對戰訓練episodes局，雙方輪流先手，opponent為nil時為自我對弈
+// 對手的行動也會以對手的角度更新Q表，統計以agent這一方的角度計算(自我對弈時以agent.Token的角度)
+func (a *QAgent) TrainAgainst(opponent Policy, episodes, reportInterval int, report func(TrainReport)) {
+	var stats Stats
+	for trainNO := 0; trainNO < episodes; trainNO++ {
+		agentToken := a.Token
+		if opponent != nil {
+			agentToken = 1 + trainNO%2 // 偶數局agent先手(O)，奇數局後手(X)
+		}
+		stats.add(a.trainEpisode(agentToken, opponent))
+		a.Episodes++
+
+		if report != nil && reportInterval > 0 && (trainNO+1)%reportInterval == 0 {
+			report(TrainReport{
+				From:            trainNO - reportInterval + 2,
+				To:              trainNO + 1,
+				Stats:           stats,
+				ExplorationRate: a.explorationRate,
+			})
+			stats = Stats{}
+		}
+		a.explorationRate *= a.Config.ExplorationDecayRate //降低探索率
+	}
+}
+
+// 訓練一局，agent下agentToken這一方，opponent為nil時雙方都用同一張Q表與探索率行動(自我對弈)
+// 每一步都以行動方的角度更新Q表
+func (a *QAgent) trainEpisode(agentToken int, opponent Policy) GameState {
+	state := ticTacToe.State{}
+	token := 1 // O先手，Q表只包含O先手能到達的棋局
+
+	for finished, _ := ticTacToe.IsGameFinished(state); !finished; finished, _ = ticTacToe.IsGameFinished(state) { //行動迴圈
+		if token == agentToken || opponent == nil {
+			state = a.Observe(token, state, a.Act(state))
+		} else {
+			state = a.Observe(token, state, opponent(state))
+		}
+		token = 3 - token
+	}
+	return CheckGameState(agentToken, state)
+}
+
+// 讓agent(不探索)跟隨機玩家對戰games局並統計結果，雙方輪流先手，不會更新Q表
+func (a *QAgent) Evaluate(games int) Stats {
+	return a.EvaluateAgainst(a.RandomPolicy(), games)
+}
+
+// 讓agent(不探索)跟opponent對戰games局並統計結果，雙方輪流先手，不會更新Q表
+func (a *QAgent) EvaluateAgainst(opponent Policy, games int) Stats {
+	var stats Stats
+	for i := 0; i < games; i++ {
+		agentToken := 1 + i%2 // 偶數局agent先手(O)，奇數局後手(X)
+		state := ticTacToe.State{}
+		token := 1
+		for finished, _ := ticTacToe.IsGameFinished(state); !finished; finished, _ = ticTacToe.IsGameFinished(state) {
+			if token == agentToken {
+				state, _ = DoAction(token, state, a.BestAction(state))
+			} else {
+				state, _ = DoAction(token, state, opponent(state))
+			}
+			token = 3 - token
+		}
+		stats.add(CheckGameState(agentToken, state))
+	}
+	return stats
+}
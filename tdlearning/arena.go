@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"tdlearning/agent"
+	"time"
+
+	arena "mcts/arena"
+	mcts "mcts/mcts"
+	rating "mcts/rating"
+	tictactoe "mcts/tictactoe"
+)
+
+// 依照玩家描述建立對戰場的玩家
+// random | minimax | human | mcts[:迭代次數或時間] | q[:Q表檔案(.json或gob格式)]
+// mcts-h[:迭代次數或時間](啟發式模擬) | mcts-q[:迭代次數或時間](以QTableFile的Q表引導模擬)
+func parsePlayer(spec string, config TrainerConfig, rng *rand.Rand, stdin *bufio.Reader) (arena.Player, error) {
+	name, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, arg = spec[:i], spec[i+1:]
+	}
+
+	switch name {
+	case "random":
+		return arena.RandomPlayer{Rand: rng}, nil
+	case "minimax":
+		return tictactoe.PerfectPlayer{Rand: rng}, nil
+	case "human":
+		return arena.HumanPlayer{
+			In:     stdin,
+			Out:    os.Stdout,
+			Render: func(game mcts.Game) string { return game.(*tictactoe.GameState).DrawTable() },
+			Parse:  strconv.Atoi,
+		}, nil
+	case "mcts":
+		return parseMCTSPlayer(arg, nil, "", rng)
+	case "mcts-h":
+		return parseMCTSPlayer(arg, mcts.HeuristicRollout{}, "mcts-h", rng)
+	case "mcts-q":
+		qTable, _, err := loadQTable(config.QTableFile)
+		if err != nil {
+			return nil, err
+		}
+		rollout := agent.Rollout{Agent: agent.New(qTable, config.Config, rng), Epsilon: 0.1}
+		return parseMCTSPlayer(arg, rollout, "mcts-q", rng)
+	case "q":
+		filename := config.QTableFile
+		if arg != "" {
+			filename = arg
+		}
+		qTable, _, err := loadQTable(filename)
+		if err != nil {
+			return nil, err
+		}
+		return agent.Player{Agent: agent.New(qTable, config.Config, rng), Label: "q(" + filename + ")"}, nil
+	}
+	return nil, fmt.Errorf("未知的玩家: %q", spec)
+}
+
+// 依預算(迭代次數或時間，空字串時為1000次迭代)建立MCTS玩家，label非空時加上預算作為顯示名稱
+func parseMCTSPlayer(arg string, rollout mcts.RolloutPolicy, label string, rng *rand.Rand) (arena.Player, error) {
+	player := arena.MCTSPlayer{Iterations: arena.DefaultIterations, Rollout: rollout, Rand: rng}
+	if arg != "" {
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			player.Iterations = n
+		} else if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+			player.Iterations, player.Duration = 0, d
+		} else {
+			return nil, fmt.Errorf("無效的MCTS預算(迭代次數或時間，例如1000或50ms): %q", arg)
+		}
+	}
+	if label != "" {
+		if player.Duration > 0 {
+			player.Label = fmt.Sprintf("%s(%v)", label, player.Duration)
+		} else {
+			player.Label = fmt.Sprintf("%s(%d)", label, player.Iterations)
+		}
+	}
+	return player, nil
+}
+
+// 讓兩個玩家在井字棋上對戰並輸出結果
+func RunArena(config TrainerConfig) {
+	rng := newRand(config)
+	stdin := bufio.NewReader(os.Stdin)
+	playerA, err := parsePlayer(config.ArenaPlayerA, config, rng, stdin)
+	if err != nil {
+		fmt.Printf("建立玩家A失敗：%v\n", err)
+		return
+	}
+	playerB, err := parsePlayer(config.ArenaPlayerB, config, rng, stdin)
+	if err != nil {
+		fmt.Printf("建立玩家B失敗：%v\n", err)
+		return
+	}
+
+	newGame := func() mcts.Game { return tictactoe.New() }
+	result, err := arena.Play(newGame, playerA, playerB, arena.Options{
+		Games:        config.EvaluateGames,
+		OpeningPlies: config.ArenaOpeningPlies,
+	})
+	if err != nil {
+		fmt.Printf("對戰失敗：%v\n", err)
+		return
+	}
+	if config.ArenaJSON {
+		err = result.WriteJSON(os.Stdout)
+	} else {
+		err = result.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Printf("輸出結果失敗：%v\n", err)
+	}
+}
+
+// 讓登記的玩家在井字棋上循環對戰，更新並儲存Elo積分榜
+func RunLadder(config TrainerConfig) {
+	rng := newRand(config)
+	var players []arena.Player
+	for _, spec := range strings.Split(config.LadderPlayers, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if spec == "human" {
+			fmt.Println("ladder不支援真人玩家")
+			return
+		}
+		player, err := parsePlayer(spec, config, rng, nil)
+		if err != nil {
+			fmt.Printf("建立玩家失敗：%v\n", err)
+			return
+		}
+		players = append(players, player)
+	}
+
+	ladder, err := rating.LoadLadder(config.LadderFile)
+	if err != nil {
+		fmt.Printf("讀取積分榜失敗：%v\n", err)
+		return
+	}
+	newGame := func() mcts.Game { return tictactoe.New() }
+	if err := ladder.RoundRobin(newGame, players, config.LadderGames); err != nil {
+		fmt.Printf("對戰失敗：%v\n", err)
+		return
+	}
+	if err := ladder.Save(config.LadderFile); err != nil {
+		fmt.Printf("寫入積分榜失敗：%v\n", err)
+	}
+	ladder.WriteLeaderboard(os.Stdout)
+}
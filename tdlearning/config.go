@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"tdlearning/agent"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 訓練與對戰的設定，可以從設定檔(json/yaml)讀取，再以命令列參數覆蓋
+type TrainerConfig struct {
+	agent.Config `yaml:",inline"` // Q-learning超參數
+
+	TrainTimes           int    `json:"trainTimes" yaml:"trainTimes"`                     // 訓練次數(遊戲次數)
+	TrainOpponent        string `json:"trainOpponent" yaml:"trainOpponent"`               // 訓練時的對手(self:自我對弈 random:隨機玩家 perfect:完美玩家)
+	CheckWinRateInterval int    `json:"checkWinRateInterval" yaml:"checkWinRateInterval"` // 每X局訓練遊戲後報告一次智能體勝率
+	LearnFromRealPlayer  bool   `json:"learnFromRealPlayer" yaml:"learnFromRealPlayer"`   // 是否從跟玩家對戰中繼續學習
+	EvaluateGames        int    `json:"evaluateGames" yaml:"evaluateGames"`               // evaluate與arena時的對戰局數
+	ArenaPlayerA         string `json:"arenaPlayerA" yaml:"arenaPlayerA"`                 // arena的玩家A(random/minimax/human/mcts[:預算]/mcts-h[:預算]/mcts-q[:預算]/q[:Q表檔案])
+	ArenaPlayerB         string `json:"arenaPlayerB" yaml:"arenaPlayerB"`                 // arena的玩家B
+	ArenaOpeningPlies    int    `json:"arenaOpeningPlies" yaml:"arenaOpeningPlies"`       // arena以前幾手統計開局
+	ArenaJSON            bool   `json:"arenaJSON" yaml:"arenaJSON"`                       // arena是否以json格式輸出結果
+	LadderPlayers        string `json:"ladderPlayers" yaml:"ladderPlayers"`               // ladder登記的玩家，以逗號分隔(格式同arena的玩家)
+	LadderFile           string `json:"ladderFile" yaml:"ladderFile"`                     // ladder的積分榜檔案(json格式)
+	LadderGames          int    `json:"ladderGames" yaml:"ladderGames"`                   // ladder每對玩家的對戰局數
+	QTableFile           string `json:"qTableFile" yaml:"qTableFile"`                     // Q表檔案(副檔名為.json時為json格式，其他為gob格式)
+	ExportFile           string `json:"exportFile" yaml:"exportFile"`                     // export時輸出的Q表檔案(json格式)
+	Seed                 int64  `json:"seed" yaml:"seed"`                                 // 亂數種子，0時使用目前時間；相同種子會得到相同的訓練結果與對局
+}
+
+// 預設設定(原本寫死在程式中的常數)
+func DefaultTrainerConfig() TrainerConfig {
+	return TrainerConfig{
+		Config:               agent.DefaultConfig(),
+		TrainTimes:           100000,
+		TrainOpponent:        "self",
+		CheckWinRateInterval: 100,
+		LearnFromRealPlayer:  false,
+		EvaluateGames:        1000,
+		ArenaPlayerA:         "q",
+		ArenaPlayerB:         "random",
+		ArenaOpeningPlies:    2,
+		LadderPlayers:        "q,minimax,mcts:100,mcts:1000,random",
+		LadderFile:           "ratings.json",
+		LadderGames:          20,
+		QTableFile:           "qtable.gob",
+		ExportFile:           "qtable.json",
+	}
+}
+
+// 從設定檔讀取設定，依副檔名判斷格式(.json/.yaml/.yml)，檔案中沒寫到的欄位沿用預設值
+func LoadTrainerConfig(filename string) (TrainerConfig, error) {
+	config := DefaultTrainerConfig()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return config, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	default:
+		return config, fmt.Errorf("不支援的設定檔格式: %s", filename)
+	}
+	if err != nil {
+		return config, fmt.Errorf("%s: %w", filename, err)
+	}
+	return config, config.Validate()
+}
+
+// 檢查設定值是否在合理範圍內
+func (config TrainerConfig) Validate() error {
+	switch {
+	case config.LearningRate <= 0 || config.LearningRate > 1:
+		return fmt.Errorf("learningRate必須介於(0,1]之間: %v", config.LearningRate)
+	case config.DiscountFactor < 0 || config.DiscountFactor > 1:
+		return fmt.Errorf("discountFactor必須介於[0,1]之間: %v", config.DiscountFactor)
+	case config.ExplorationRate < 0 || config.ExplorationRate > 1:
+		return fmt.Errorf("explorationRate必須介於[0,1]之間: %v", config.ExplorationRate)
+	case config.ExplorationDecayRate < 0 || config.ExplorationDecayRate > 1:
+		return fmt.Errorf("explorationDecayRate必須介於[0,1]之間: %v", config.ExplorationDecayRate)
+	case config.TrainOpponent != "self" && config.TrainOpponent != "random" && config.TrainOpponent != "perfect":
+		return fmt.Errorf("trainOpponent必須是self、random或perfect: %q", config.TrainOpponent)
+	case config.CheckWinRateInterval <= 0:
+		return fmt.Errorf("checkWinRateInterval必須大於0: %v", config.CheckWinRateInterval)
+	}
+	return nil
+}
+
+// 將設定綁定到命令列參數
+func (config *TrainerConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&config.LearningRate, "learning-rate", config.LearningRate, "學習率 (0,1]")
+	fs.Float64Var(&config.DiscountFactor, "discount-factor", config.DiscountFactor, "折扣係數 [0,1]")
+	fs.Float64Var(&config.ExplorationRate, "exploration-rate", config.ExplorationRate, "初始探索率 [0,1]")
+	fs.Float64Var(&config.ExplorationDecayRate, "exploration-decay-rate", config.ExplorationDecayRate, "每局結束後探索率乘上的衰減值")
+	fs.Float64Var(&config.Rewards.Win, "win-reward", config.Rewards.Win, "勝利時的獎勵")
+	fs.Float64Var(&config.Rewards.Draw, "draw-reward", config.Rewards.Draw, "平手時的獎勵")
+	fs.Float64Var(&config.Rewards.Loss, "loss-reward", config.Rewards.Loss, "失敗時的獎勵")
+	fs.IntVar(&config.TrainTimes, "train-times", config.TrainTimes, "訓練次數(遊戲次數)")
+	fs.StringVar(&config.TrainOpponent, "train-opponent", config.TrainOpponent, "訓練時的對手(self/random/perfect)")
+	fs.IntVar(&config.CheckWinRateInterval, "check-win-rate-interval", config.CheckWinRateInterval, "每幾局訓練報告一次勝率")
+	fs.BoolVar(&config.LearnFromRealPlayer, "learn-from-real-player", config.LearnFromRealPlayer, "跟玩家對戰時是否繼續學習並寫回Q表")
+	fs.IntVar(&config.EvaluateGames, "evaluate-games", config.EvaluateGames, "evaluate與arena時的對戰局數")
+	fs.StringVar(&config.ArenaPlayerA, "a", config.ArenaPlayerA, "arena的玩家A(random/minimax/human/mcts[:預算]/mcts-h[:預算]/mcts-q[:預算]/q[:Q表檔案])")
+	fs.StringVar(&config.ArenaPlayerB, "b", config.ArenaPlayerB, "arena的玩家B(random/minimax/human/mcts[:預算]/mcts-h[:預算]/mcts-q[:預算]/q[:Q表檔案])")
+	fs.IntVar(&config.ArenaOpeningPlies, "opening-plies", config.ArenaOpeningPlies, "arena以前幾手統計開局，0代表不統計")
+	fs.BoolVar(&config.ArenaJSON, "json", config.ArenaJSON, "arena以json格式輸出結果")
+	fs.StringVar(&config.LadderPlayers, "players", config.LadderPlayers, "ladder登記的玩家，以逗號分隔(格式同arena的玩家)")
+	fs.StringVar(&config.LadderFile, "ladder-file", config.LadderFile, "ladder的積分榜檔案(json格式)")
+	fs.IntVar(&config.LadderGames, "ladder-games", config.LadderGames, "ladder每對玩家的對戰局數")
+	fs.StringVar(&config.QTableFile, "qtable", config.QTableFile, "Q表檔案(副檔名為.json時為json格式，其他為gob格式)")
+	fs.StringVar(&config.ExportFile, "export-file", config.ExportFile, "export時輸出的Q表檔案(json格式)")
+	fs.Int64Var(&config.Seed, "seed", config.Seed, "亂數種子，0時使用目前時間")
+}
+
+// 解析子命令的參數，優先順序為 命令列參數 > 設定檔(-config) > 預設值
+func ParseTrainerConfig(name string, args []string) (TrainerConfig, error) {
+	config := DefaultTrainerConfig()
+	configFile := ""
+	newFlagSet := func(config *TrainerConfig) *flag.FlagSet {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		fs.StringVar(&configFile, "config", configFile, "設定檔路徑(.json/.yaml/.yml)")
+		config.RegisterFlags(fs)
+		return fs
+	}
+
+	if err := newFlagSet(&config).Parse(args); err != nil {
+		return config, err
+	}
+	if configFile == "" {
+		return config, config.Validate()
+	}
+
+	// 讀取設定檔後再解析一次命令列參數，讓命令列參數覆蓋設定檔的值
+	config, err := LoadTrainerConfig(configFile)
+	if err != nil {
+		return config, err
+	}
+	if err := newFlagSet(&config).Parse(args); err != nil {
+		return config, err
+	}
+	return config, config.Validate()
+}
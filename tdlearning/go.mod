@@ -1,3 +1,10 @@
 module tdlearning
 
 go 1.18
+
+require (
+	gopkg.in/yaml.v3 v3.0.1
+	mcts v0.0.0
+)
+
+replace mcts => ../mcts
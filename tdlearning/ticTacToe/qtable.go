@@ -6,55 +6,46 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // 存每個行動的Q值
 type ActionQ map[int]float64
 
-// 存每個狀態和對應的行動價值
+// 存每個狀態和對應的行動價值，狀態與行動都以標準形(Canonicalize)儲存，查表前需先轉換
 type QTable map[State]ActionQ
 
-//輸出json時轉換換用類型
-type ExportableQTable struct {
-	States map[string]map[string]float64
-}
+// json格式的版本號，格式變動時遞增，讀取時版本不符會返回錯誤
+const QTableJsonVersion = 1
 
-//取絕對值函式 註:很意外math庫的Abs沒有傳入int的函式
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
+// Q表的訓練資訊，隨json一起輸出
+type QTableMetadata struct {
+	LearningRate    float64 `json:"learningRate"`    // 學習率
+	DiscountFactor  float64 `json:"discountFactor"`  // 折扣係數
+	EpisodesTrained int     `json:"episodesTrained"` // 已訓練的遊戲次數
 }
 
-// 窮舉並產生所有可能的棋局(放滿棋盤才算一個棋局)
-func generateStates(state [9]int, index int, states *[]State) {
-
-	// 如果index是棋局中的第幾步，index==9，代表完成一盤棋了
-	if index == 9 {
-		oCount := Count(1, state)    //計算O的數量
-		xCount := Count(2, state)    //計算X的數量
-		if abs(oCount-xCount) <= 1 { //O和X的數量差不大於1才合法，才加到紀錄中
-			*states = append(*states, state)
-		}
-		return
-	}
-
-	for i := 0; i < 3; i++ { // 空格可能的內容(0:空格 1:圈圈 2:叉叉)
-		state[index] = i
-		generateStates(state, index+1, states)
-	}
+// 輸出json時轉換換用類型 States的key為State.ToKeyString()的結果(例如"0|1|2|0|0|0|0|0|0")，value的key為行動位置("0"~"8")
+type ExportableQTable struct {
+	Version  int                           `json:"version"`
+	Metadata QTableMetadata                `json:"metadata"`
+	States   map[string]map[string]float64 `json:"states"`
 }
 
-// 初始化Q表
+// 初始化Q表，只包含可到達且尚未結束的棋局，對稱的棋局只保留一筆標準形
 func InitQTable() QTable {
 	qTable := make(QTable) //每個狀態對應的行動價值mpa
 
-	var state [9]int
-	states := []State{} //棋盤狀態slice用[9]int來標示每一格填入的是O還是X
-	// 生成所有可能的棋盤狀態
-	generateStates(state, 0, &states)
-	for _, state := range states {
+	// 列舉所有可到達的棋盤狀態
+	EnumerateStates().ForEach(func(state State, info StateInfo) bool {
+		if info.IsTerminal { // 已結束的棋局沒有下一步，maxQ查不到時會返回0
+			return true
+		}
+		state, _ = Canonicalize(state)
+		if _, ok := qTable[state]; ok { // 同一個等價類已經加入過了
+			return true
+		}
 		actions := make(ActionQ)                //actions是map[int]float64用來存每個行動的Q值
 		for action := 0; action < 9; action++ { //OOXX有9個格子所以每盤棋會有9次行動
 			// 如果該位置為空格，則行動價值初始化為0
@@ -64,13 +55,24 @@ func InitQTable() QTable {
 		}
 		// 將當前狀態的行動價值添加到Q表中
 		qTable[state] = actions
-	}
+		return true
+	})
 
 	return qTable
 }
 
-// 寫入Q表到本地(gob格式)
-func SaveQTableToGob(qTable QTable, filename string) error {
+// gob格式的版本號，格式變動時遞增，讀取時版本不符會返回錯誤
+const QTableGobVersion = 1
+
+// gob檔案的內容，訓練資訊跟Q表存在一起
+type gobQTable struct {
+	Version  int
+	Metadata QTableMetadata
+	Table    QTable
+}
+
+// 寫入Q表與訓練資訊到本地(gob格式)
+func SaveQTableToGob(qTable QTable, metadata QTableMetadata, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -78,7 +80,7 @@ func SaveQTableToGob(qTable QTable, filename string) error {
 	defer file.Close()
 
 	encoder := gob.NewEncoder(file)
-	err = encoder.Encode(qTable)
+	err = encoder.Encode(gobQTable{Version: QTableGobVersion, Metadata: metadata, Table: qTable})
 	if err != nil {
 		return err
 	}
@@ -86,27 +88,30 @@ func SaveQTableToGob(qTable QTable, filename string) error {
 	return nil
 }
 
-// 從本地讀取Q表(gob格式)
-func LoadQTableFromGob(filename string) (QTable, error) {
+// 從本地讀取Q表與訓練資訊(gob格式)，沒有版本號的舊格式或版本不符時返回錯誤
+func LoadQTableFromGob(filename string) (QTable, QTableMetadata, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return nil, QTableMetadata{}, err
 	}
 	defer file.Close()
 
-	var qTable QTable
+	var content gobQTable
 	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(&qTable)
+	err = decoder.Decode(&content)
 	if err != nil {
-		return nil, err
+		return nil, QTableMetadata{}, fmt.Errorf("%s: 無法讀取Q表(沒有版本號的舊格式需要重新訓練): %w", filename, err)
+	}
+	if content.Version != QTableGobVersion {
+		return nil, QTableMetadata{}, fmt.Errorf("%s: 不支援的Q表版本 %d (目前版本為 %d)", filename, content.Version, QTableGobVersion)
 	}
 
-	return qTable, nil
+	return content.Table, content.Metadata, nil
 }
 
 // 寫入Q表到本地(json格式)
-func SaveQTableToJson(qTable QTable, filename string) error {
-	exportableQTable := ConvertQTableToExportable(qTable)
+func SaveQTableToJson(qTable QTable, metadata QTableMetadata, filename string) error {
+	exportableQTable := ConvertQTableToExportable(qTable, metadata)
 
 	jsonData, err := json.Marshal(exportableQTable)
 	if err != nil {
@@ -121,27 +126,32 @@ func SaveQTableToJson(qTable QTable, filename string) error {
 }
 
 // 從本地讀取Q表(json格式)並轉換回QTable
-func LoadQTableFromJson(filename string) (QTable, error) {
+func LoadQTableFromJson(filename string) (QTable, QTableMetadata, error) {
 	jsonData, err := ioutil.ReadFile(filename)
 	if err != nil {
-		return nil, err
+		return nil, QTableMetadata{}, err
 	}
 
 	var exportableQTable ExportableQTable
 	err = json.Unmarshal(jsonData, &exportableQTable)
 	if err != nil {
-		return nil, err
+		return nil, QTableMetadata{}, err
 	}
 
-	qTable := ConvertExportableToQTable(exportableQTable)
+	qTable, err := ConvertExportableToQTable(exportableQTable)
+	if err != nil {
+		return nil, QTableMetadata{}, fmt.Errorf("%s: %w", filename, err)
+	}
 
-	return qTable, nil
+	return qTable, exportableQTable.Metadata, nil
 }
 
 //將Q表輸出json時轉換成ExportableQTable類型
-func ConvertQTableToExportable(qTable QTable) ExportableQTable {
+func ConvertQTableToExportable(qTable QTable, metadata QTableMetadata) ExportableQTable {
 	exportableQTable := ExportableQTable{
-		States: make(map[string]map[string]float64),
+		Version:  QTableJsonVersion,
+		Metadata: metadata,
+		States:   make(map[string]map[string]float64),
 	}
 
 	for state, actionQ := range qTable {
@@ -149,7 +159,7 @@ func ConvertQTableToExportable(qTable QTable) ExportableQTable {
 		exportableQTable.States[stateStr] = make(map[string]float64)
 
 		for action, qValue := range actionQ {
-			actionStr := fmt.Sprintf("%d", action)
+			actionStr := strconv.Itoa(action)
 			exportableQTable.States[stateStr][actionStr] = qValue
 		}
 	}
@@ -157,33 +167,47 @@ func ConvertQTableToExportable(qTable QTable) ExportableQTable {
 	return exportableQTable
 }
 
-// 將ExportableQTable類型轉換回QTable
-func ConvertExportableToQTable(exportableQTable ExportableQTable) QTable {
-	qTable := make(QTable)
+// 將ExportableQTable類型轉換回QTable，版本不符、狀態或行動格式錯誤時返回錯誤
+func ConvertExportableToQTable(exportableQTable ExportableQTable) (QTable, error) {
+	if exportableQTable.Version != QTableJsonVersion {
+		return nil, fmt.Errorf("不支援的Q表版本 %d (目前版本為 %d)", exportableQTable.Version, QTableJsonVersion)
+	}
 
+	qTable := make(QTable)
 	for stateStr, actionQ := range exportableQTable.States {
-		state := StateFromKeyString(stateStr)
+		state, err := StateFromKeyString(stateStr)
+		if err != nil {
+			return nil, err
+		}
 		actions := make(ActionQ)
 
 		for actionStr, qValue := range actionQ {
-			action := 0
-			fmt.Sscanf(actionStr, "%d", &action)
+			action, err := strconv.Atoi(actionStr)
+			if err != nil || action < 0 || action >= len(state) {
+				return nil, fmt.Errorf("狀態 %q 中有無效的行動 %q", stateStr, actionStr)
+			}
 			actions[action] = qValue
 		}
 
 		qTable[state] = actions
 	}
 
-	return qTable
+	return qTable, nil
 }
 
-// 從狀態字串中建立State
-func StateFromKeyString(stateStr string) State {
+// 從狀態字串(State.ToKeyString的格式)中建立State，格式錯誤時返回錯誤
+func StateFromKeyString(stateStr string) (State, error) {
 	var state State
-	fmt.Sscanf(stateStr, "%d%d%d%d%d%d%d%d%d",
-		&state[0], &state[1], &state[2],
-		&state[3], &state[4], &state[5],
-		&state[6], &state[7], &state[8],
-	)
-	return state
+	cells := strings.Split(stateStr, "|")
+	if len(cells) != len(state) {
+		return state, fmt.Errorf("無效的狀態字串 %q: 需要 %d 格，實際為 %d 格", stateStr, len(state), len(cells))
+	}
+	for i, cell := range cells {
+		token, err := strconv.Atoi(cell)
+		if err != nil || token < 0 || token > 2 {
+			return state, fmt.Errorf("無效的狀態字串 %q: 第 %d 格為 %q", stateStr, i, cell)
+		}
+		state[i] = token
+	}
+	return state, nil
 }
@@ -0,0 +1,83 @@
+package ticTacToe
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQTableJsonRoundTrip(t *testing.T) {
+	qTable := InitQTable()
+	value := 0.0
+	for _, actions := range qTable {
+		for action := range actions {
+			value += 0.001
+			actions[action] = value
+		}
+	}
+	metadata := QTableMetadata{LearningRate: 0.5, DiscountFactor: 0.7, EpisodesTrained: 12345}
+
+	filename := filepath.Join(t.TempDir(), "qtable.json")
+	if err := SaveQTableToJson(qTable, metadata, filename); err != nil {
+		t.Fatalf("SaveQTableToJson: %v", err)
+	}
+	loaded, loadedMetadata, err := LoadQTableFromJson(filename)
+	if err != nil {
+		t.Fatalf("LoadQTableFromJson: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, qTable) {
+		t.Errorf("讀回的Q表與寫入的不同: %d筆 / %d筆", len(loaded), len(qTable))
+	}
+	if loadedMetadata != metadata {
+		t.Errorf("讀回的訓練資訊為 %+v，預期為 %+v", loadedMetadata, metadata)
+	}
+}
+
+func TestQTableGobRoundTrip(t *testing.T) {
+	qTable := InitQTable()
+	qTable[State{}][4] = 0.25
+	metadata := QTableMetadata{LearningRate: 0.5, DiscountFactor: 0.7, EpisodesTrained: 100}
+
+	filename := filepath.Join(t.TempDir(), "qtable.gob")
+	if err := SaveQTableToGob(qTable, metadata, filename); err != nil {
+		t.Fatalf("SaveQTableToGob: %v", err)
+	}
+	loaded, loadedMetadata, err := LoadQTableFromGob(filename)
+	if err != nil {
+		t.Fatalf("LoadQTableFromGob: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, qTable) || loadedMetadata != metadata {
+		t.Errorf("讀回的Q表或訓練資訊與寫入的不同")
+	}
+}
+
+func TestConvertExportableToQTableRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		states  map[string]map[string]float64
+		want    string
+	}{
+		{"錯誤版本", QTableJsonVersion + 1, nil, "版本"},
+		{"沒有版本", 0, nil, "版本"},
+		{"格數不足", QTableJsonVersion, map[string]map[string]float64{"0|0|0": {}}, "無效的狀態字串"},
+		{"沒有分隔符", QTableJsonVersion, map[string]map[string]float64{"000000000": {}}, "無效的狀態字串"},
+		{"無效的棋子", QTableJsonVersion, map[string]map[string]float64{"0|0|0|0|3|0|0|0|0": {}}, "無效的狀態字串"},
+		{"非數字的格子", QTableJsonVersion, map[string]map[string]float64{"0|0|0|0|x|0|0|0|0": {}}, "無效的狀態字串"},
+		{"非數字的行動", QTableJsonVersion, map[string]map[string]float64{"0|0|0|0|0|0|0|0|0": {"a": 1}}, "無效的行動"},
+		{"超出範圍的行動", QTableJsonVersion, map[string]map[string]float64{"0|0|0|0|0|0|0|0|0": {"9": 1}}, "無效的行動"},
+		{"負的行動", QTableJsonVersion, map[string]map[string]float64{"0|0|0|0|0|0|0|0|0": {"-1": 1}}, "無效的行動"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qTable, err := ConvertExportableToQTable(ExportableQTable{Version: test.version, States: test.states})
+			if err == nil {
+				t.Fatalf("預期錯誤，實際讀到 %d 筆狀態", len(qTable))
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("錯誤訊息 %q 沒有包含 %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package ticTacToe
+
+// 棋局資訊(是否結束與贏家token，0代表平手或未結束)
+type StateInfo struct {
+	IsTerminal bool
+	Winner     int
+}
+
+// 從空棋盤出發(O先手)實際下棋能到達的所有棋局
+type StateSpace struct {
+	infos  map[State]StateInfo
+	states []State // 依發現順序記錄，讓迭代順序固定
+}
+
+// 走訪整棵遊戲樹列舉所有可到達的棋局，棋局結束後不再往下走
+func EnumerateStates() *StateSpace {
+	space := &StateSpace{infos: make(map[State]StateInfo)}
+	space.walk(State{}, 1)
+	return space
+}
+
+// 深度優先走訪，token為目前要下的棋子(1:圈圈 2:叉叉)
+func (space *StateSpace) walk(state State, token int) {
+	if _, ok := space.infos[state]; ok { // 不同下法可能走到同一個棋局
+		return
+	}
+	isTerminal, winner := IsGameFinished(state)
+	space.infos[state] = StateInfo{isTerminal, winner}
+	space.states = append(space.states, state)
+	if isTerminal {
+		return
+	}
+
+	for action, v := range state {
+		if v == 0 {
+			next := state
+			next[action] = token
+			space.walk(next, 3-token)
+		}
+	}
+}
+
+// 可到達的棋局總數
+func (space *StateSpace) Len() int {
+	return len(space.states)
+}
+
+// 已結束的棋局數量
+func (space *StateSpace) TerminalCount() int {
+	cnt := 0
+	for _, info := range space.infos {
+		if info.IsTerminal {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// 指定贏家的已結束棋局數量(winner傳入0就是計算平手局)
+func (space *StateSpace) WinCount(winner int) int {
+	cnt := 0
+	for _, info := range space.infos {
+		if info.IsTerminal && info.Winner == winner {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// 取得棋局資訊，第二個回傳值為false時代表此棋局不可能出現
+func (space *StateSpace) Info(state State) (StateInfo, bool) {
+	info, ok := space.infos[state]
+	return info, ok
+}
+
+// 判斷棋局是否能從空棋盤到達
+func (space *StateSpace) Contains(state State) bool {
+	_, ok := space.infos[state]
+	return ok
+}
+
+// 依固定順序走訪每個棋局，fn回傳false時停止
+func (space *StateSpace) ForEach(fn func(state State, info StateInfo) bool) {
+	for _, state := range space.states {
+		if !fn(state, space.infos[state]) {
+			return
+		}
+	}
+}
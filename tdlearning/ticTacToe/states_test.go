@@ -0,0 +1,20 @@
+package ticTacToe
+
+import "testing"
+
+func TestEnumerateStatesCounts(t *testing.T) {
+	space := EnumerateStates()
+	if got := space.Len(); got != 5478 {
+		t.Errorf("可到達的棋局數為 %d，預期為 5478", got)
+	}
+	if got := space.TerminalCount(); got != 958 {
+		t.Errorf("已結束的棋局數為 %d，預期為 958", got)
+	}
+	// 958個結束的棋局中先手勝626、後手勝316、和局16
+	if o, x := space.WinCount(1), space.WinCount(2); o != 626 || x != 316 {
+		t.Errorf("先手勝%d、後手勝%d，預期為626、316", o, x)
+	}
+	if !space.Contains(State{}) || space.Contains(State{2, 0, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Error("空棋盤應該可到達，叉叉先下的棋局不可到達")
+	}
+}
@@ -0,0 +1,70 @@
+package ticTacToe
+
+// 棋盤的對稱變換編號(四種旋轉與四種鏡射)，同一個等價類的棋局只需在Q表中存一筆
+type Symmetry int
+
+// 八種對稱變換，symmetries[s][i]代表變換後第i格的棋子來自原棋盤的哪一格
+var symmetries = [8][9]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8}, // 不變
+	{6, 3, 0, 7, 4, 1, 8, 5, 2}, // 順時針旋轉90度
+	{8, 7, 6, 5, 4, 3, 2, 1, 0}, // 旋轉180度
+	{2, 5, 8, 1, 4, 7, 0, 3, 6}, // 順時針旋轉270度
+	{2, 1, 0, 5, 4, 3, 8, 7, 6}, // 左右鏡射
+	{6, 7, 8, 3, 4, 5, 0, 1, 2}, // 上下鏡射
+	{0, 3, 6, 1, 4, 7, 2, 5, 8}, // 沿主對角線鏡射
+	{8, 5, 2, 7, 4, 1, 6, 3, 0}, // 沿副對角線鏡射
+}
+
+// symmetries的反向對照，inverseSymmetries[s][p]代表原棋盤第p格在變換後的位置
+var inverseSymmetries [8][9]int
+
+func init() {
+	for s, symmetry := range symmetries {
+		for i, p := range symmetry {
+			inverseSymmetries[s][p] = i
+		}
+	}
+}
+
+// 對棋局套用對稱變換
+func (s Symmetry) Apply(state State) State {
+	var transformed State
+	for i, p := range symmetries[s] {
+		transformed[i] = state[p]
+	}
+	return transformed
+}
+
+// 將原棋盤上的行動位置轉換成變換後(標準形)棋盤上的位置
+func (s Symmetry) ToCanonical(action int) int {
+	return inverseSymmetries[s][action]
+}
+
+// 將變換後(標準形)棋盤上的行動位置轉換回原棋盤上的位置
+func (s Symmetry) FromCanonical(action int) int {
+	return symmetries[s][action]
+}
+
+// 取得棋局的標準形(八種變換中字典序最小的棋局)與轉換到標準形所用的對稱變換
+func Canonicalize(state State) (State, Symmetry) {
+	canonical := state
+	symmetry := Symmetry(0)
+	for s := Symmetry(1); s < Symmetry(len(symmetries)); s++ {
+		transformed := s.Apply(state)
+		if lessState(transformed, canonical) {
+			canonical = transformed
+			symmetry = s
+		}
+	}
+	return canonical, symmetry
+}
+
+// 以字典序比較兩個棋局
+func lessState(a, b State) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
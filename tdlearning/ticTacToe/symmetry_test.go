@@ -0,0 +1,50 @@
+package ticTacToe
+
+import "testing"
+
+func TestSymmetryActionRoundTrip(t *testing.T) {
+	for s := Symmetry(0); s < Symmetry(len(symmetries)); s++ {
+		for action := 0; action < 9; action++ {
+			if got := s.FromCanonical(s.ToCanonical(action)); got != action {
+				t.Errorf("對稱變換%d: FromCanonical(ToCanonical(%d)) = %d", s, action, got)
+			}
+		}
+	}
+}
+
+// 行動位置的轉換要跟棋盤的轉換一致：原棋盤第a格的棋子在變換後位於ToCanonical(a)
+func TestSymmetryActionMatchesApply(t *testing.T) {
+	state := State{1, 2, 0, 0, 1, 0, 2, 0, 0}
+	for s := Symmetry(0); s < Symmetry(len(symmetries)); s++ {
+		transformed := s.Apply(state)
+		for action := 0; action < 9; action++ {
+			if transformed[s.ToCanonical(action)] != state[action] {
+				t.Errorf("對稱變換%d: 第%d格的棋子沒有移到ToCanonical的位置", s, action)
+			}
+		}
+	}
+}
+
+// 同一個等價類的八種變換都要得到相同的標準形，且返回的變換能把棋局轉成標準形
+func TestCanonicalizeIsInvariant(t *testing.T) {
+	EnumerateStates().ForEach(func(state State, info StateInfo) bool {
+		canonical, _ := Canonicalize(state)
+		for s := Symmetry(0); s < Symmetry(len(symmetries)); s++ {
+			got, symmetry := Canonicalize(s.Apply(state))
+			if got != canonical {
+				t.Fatalf("%v 經過對稱變換%d後的標準形為 %v，預期為 %v", state, s, got, canonical)
+			}
+			if symmetry.Apply(s.Apply(state)) != got {
+				t.Fatalf("Canonicalize返回的對稱變換%d沒有把棋局轉成標準形", symmetry)
+			}
+		}
+		return true
+	})
+}
+
+// 5478個可到達的棋局扣掉958個已結束的，依對稱合併後剩627個等價類
+func TestInitQTableSize(t *testing.T) {
+	if got := len(InitQTable()); got != 627 {
+		t.Errorf("len(InitQTable()) = %d，預期為 627", got)
+	}
+}